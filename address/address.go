@@ -0,0 +1,148 @@
+package address
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrMalformed = errors.New("malformed ton address")
+	ErrChecksum  = errors.New("ton address checksum mismatch")
+)
+
+const (
+	tagBounceable    = 0x11
+	tagNonBounceable = 0x51
+	tagTestnet       = 0x80
+
+	friendlyLen = 36
+)
+
+// Address is a TON smart contract address.
+type Address struct {
+	Workchain int32
+	Hash      [32]byte
+	// Bounceable and Testnet are the flags of a user-friendly address. A raw
+	// address parses as bounceable on mainnet.
+	Bounceable bool
+	Testnet    bool
+}
+
+// Parse accepts the raw "0:<hex>" form and the user-friendly base64 and
+// base64url forms, checking the CRC16 of the latter.
+func Parse(s string) (Address, error) {
+	if strings.Contains(s, ":") {
+		return parseRaw(s)
+	}
+	return parseFriendly(s)
+}
+
+func parseRaw(s string) (Address, error) {
+	wc, h, _ := strings.Cut(s, ":")
+	workchain, err := strconv.ParseInt(wc, 10, 32)
+	if err != nil {
+		return Address{}, errors.Wrapf(ErrMalformed, "%q: bad workchain", s)
+	}
+	var a Address
+	if len(h) != 2*len(a.Hash) {
+		return Address{}, errors.Wrapf(ErrMalformed, "%q: hash must be %d hex digits", s, 2*len(a.Hash))
+	}
+	if _, err := hex.Decode(a.Hash[:], []byte(h)); err != nil {
+		return Address{}, errors.Wrapf(ErrMalformed, "%q: bad hash", s)
+	}
+	a.Workchain = int32(workchain)
+	a.Bounceable = true
+	return a, nil
+}
+
+func parseFriendly(s string) (Address, error) {
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil || len(b) != friendlyLen {
+		return Address{}, errors.Wrapf(ErrMalformed, "%q", s)
+	}
+	if crc16(b[:34]) != binary.BigEndian.Uint16(b[34:]) {
+		return Address{}, errors.Wrapf(ErrChecksum, "%q", s)
+	}
+
+	a := Address{Testnet: b[0]&tagTestnet != 0}
+	switch b[0] &^ tagTestnet {
+	case tagBounceable:
+		a.Bounceable = true
+	case tagNonBounceable:
+	default:
+		return Address{}, errors.Wrapf(ErrMalformed, "%q: unknown tag %#x", s, b[0])
+	}
+	a.Workchain = int32(int8(b[1]))
+	copy(a.Hash[:], b[2:34])
+	return a, nil
+}
+
+// Raw formats a as "<workchain>:<lowercase hex hash>".
+func (a Address) Raw() string {
+	return strconv.FormatInt(int64(a.Workchain), 10) + ":" + hex.EncodeToString(a.Hash[:])
+}
+
+// UserFriendly formats a in the 48 character base64 form carrying its
+// Bounceable and Testnet flags, with the URL-safe alphabet when urlSafe is set.
+func (a Address) UserFriendly(urlSafe bool) string {
+	b := make([]byte, friendlyLen)
+	b[0] = tagNonBounceable
+	if a.Bounceable {
+		b[0] = tagBounceable
+	}
+	if a.Testnet {
+		b[0] |= tagTestnet
+	}
+	b[1] = byte(int8(a.Workchain))
+	copy(b[2:34], a.Hash[:])
+	binary.BigEndian.PutUint16(b[34:], crc16(b[:34]))
+	if urlSafe {
+		return base64.URLEncoding.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// String is the URL-safe user-friendly form, the one wallets and explorers show.
+func (a Address) String() string {
+	return a.UserFriendly(true)
+}
+
+// Equal reports whether a and o point to the same contract, ignoring flags.
+func (a Address) Equal(o Address) bool {
+	return a.Workchain == o.Workchain && a.Hash == o.Hash
+}
+
+// Canonical returns the raw form of s, so that every representation of an
+// address maps to the same string. Values that aren't addresses are returned
+// unchanged.
+func Canonical(s string) string {
+	if a, err := Parse(s); err == nil {
+		return a.Raw()
+	}
+	return s
+}
+
+// crc16 is CRC-16/XMODEM as used by user-friendly addresses.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
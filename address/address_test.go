@@ -0,0 +1,93 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/test-go/testify/require"
+)
+
+// Vectors were generated independently with Python's base64 and binascii.crc_hqx.
+const raw = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in         string
+		bounceable bool
+		testnet    bool
+	}{
+		{in: raw, bounceable: true},
+		{in: "0:CA6E321C7CCE9ECEDF0A8CA2492EC8592494AA5FB5CE0387DFF96EF6AF982A3E", bounceable: true},
+		{in: "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF", bounceable: true},
+		{in: "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff+W72r5gqPrHF", bounceable: true},
+		{in: "UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA"},
+		{in: "kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP", bounceable: true, testnet: true},
+		{in: "0QDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff+W72r5gqPleK", testnet: true},
+	} {
+		a, err := Parse(tc.in)
+		require.NoError(t, err, tc.in)
+		require.Equal(t, raw, a.Raw(), tc.in)
+		require.Equal(t, tc.bounceable, a.Bounceable, tc.in)
+		require.Equal(t, tc.testnet, a.Testnet, tc.in)
+	}
+
+	a, err := Parse("Ef8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM0vF")
+	require.NoError(t, err)
+	require.Equal(t, int32(-1), a.Workchain)
+	require.Equal(t, "-1:3333333333333333333333333333333333333333333333333333333333333333", a.Raw())
+}
+
+func TestParseRejects(t *testing.T) {
+	_, err := Parse("EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHG")
+	require.True(t, errors.Is(err, ErrChecksum))
+
+	for _, in := range []string{
+		"",
+		"vault-usdt",
+		"0:ca6e",
+		"x:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e",
+		"0:za6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e",
+		"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrH",
+	} {
+		_, err := Parse(in)
+		require.True(t, errors.Is(err, ErrMalformed), in)
+	}
+}
+
+func TestUserFriendly(t *testing.T) {
+	a, err := Parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF", a.String())
+	require.Equal(t, "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff+W72r5gqPrHF", a.UserFriendly(false))
+
+	a.Bounceable = false
+	require.Equal(t, "UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA", a.String())
+	a.Testnet = true
+	require.Equal(t, "0QDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPleK", a.String())
+	a.Bounceable = true
+	require.Equal(t, "kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP", a.String())
+
+	master, err := Parse("-1:3333333333333333333333333333333333333333333333333333333333333333")
+	require.NoError(t, err)
+	require.Equal(t, "Ef8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM0vF", master.String())
+
+	for _, s := range []string{"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF", "kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP"} {
+		parsed, err := Parse(s)
+		require.NoError(t, err)
+		require.Equal(t, s, parsed.String())
+		require.True(t, parsed.Equal(a))
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for _, s := range []string{
+		raw,
+		"0:CA6E321C7CCE9ECEDF0A8CA2492EC8592494AA5FB5CE0387DFF96EF6AF982A3E",
+		"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF",
+		"UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff+W72r5gqPuwA",
+	} {
+		require.Equal(t, raw, Canonical(s), s)
+	}
+	require.Equal(t, "market-btc", Canonical("market-btc"))
+	require.Equal(t, "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHG", Canonical("EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHG"))
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/address"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func testAddress(b byte) address.Address {
+	var a address.Address
+	for i := range a.Hash {
+		a.Hash[i] = b
+	}
+	a.Bounceable = true
+	return a
+}
+
+func TestAddressLookupsAcceptAnyRepresentation(t *testing.T) {
+	market, prelaunch, vault, lp := testAddress(1), testAddress(2), testAddress(3), testAddress(4)
+	usdt := types.CollateralAsset{Name: "USDT", Decimals: 6, AssetId: "usdt-id"}
+
+	s := newTestServer(t)
+	cfg := testAppConfig("2024-01-01T00:00:00Z")
+	cfg.OpenedMarkets = []types.Market{
+		{Name: "BTC/USDT", Address: market.String(), VaultAddress: vault.Raw(), BaseAsset: "BTC"},
+		{Name: "LTC/USDT", Address: prelaunch.Raw(), VaultAddress: vault.String(), BaseAsset: "LTC", Type: "prelaunch"},
+	}
+	cfg.Vaults = []types.Vault{{Asset: usdt, VaultAddress: vault.String(), LpJettonMaster: lp.UserFriendly(false)}}
+	s.set("", cfg)
+	c := newTestClient(t, s)
+	require.Empty(t, c.GetIndexCollisions())
+	require.Empty(t, c.GetValidationWarnings())
+
+	plain := func(a address.Address) string {
+		a.Bounceable = false
+		return a.String()
+	}
+	for _, addr := range []string{market.Raw(), market.String(), market.UserFriendly(false), plain(market)} {
+		require.True(t, c.HasMarketByAddress(addr), addr)
+		require.Equal(t, "BTC/USDT", c.GetMarketByAddress(addr).Name, addr)
+	}
+	for _, addr := range []string{prelaunch.Raw(), prelaunch.String(), plain(prelaunch)} {
+		require.True(t, c.HasPrelaunchMarketByAddress(addr), addr)
+		require.Equal(t, "LTC/USDT", c.GetPrelaunchMarketByAddress(addr).Name, addr)
+	}
+	for _, addr := range []string{vault.Raw(), vault.String(), plain(vault)} {
+		require.True(t, c.HasVaultByAddress(addr), addr)
+		require.Equal(t, vault.String(), c.GetVaultByAddress(addr).VaultAddress, addr)
+	}
+	for _, addr := range []string{lp.Raw(), lp.String(), lp.UserFriendly(false)} {
+		require.True(t, c.HasVaultByLpJettonMasterAddress(addr), addr)
+	}
+	require.False(t, c.HasMarketByAddress(testAddress(9).Raw()))
+
+	addresses := c.GetMarketsAddresses()
+	sort.Strings(addresses)
+	want := []string{market.String(), prelaunch.Raw()}
+	sort.Strings(want)
+	require.Equal(t, want, addresses)
+}
+
+func TestAddressIndexCollisionAcrossRepresentations(t *testing.T) {
+	vault := testAddress(3)
+	usdt := types.CollateralAsset{Name: "USDT", Decimals: 6, AssetId: "usdt-id"}
+
+	s := newTestServer(t)
+	cfg := testAppConfig("2024-01-01T00:00:00Z")
+	cfg.Vaults = []types.Vault{
+		{Asset: usdt, VaultAddress: "vault-usdt", LpJettonMaster: vault.String()},
+		{Asset: usdt, VaultAddress: "vault-usdt-2", LpJettonMaster: vault.Raw()},
+	}
+	s.set("", cfg)
+	c := newTestClient(t, s)
+
+	var lpCollisions []Collision
+	for _, col := range c.GetIndexCollisions() {
+		if col.Index == "vaults by lp jetton master" {
+			lpCollisions = append(lpCollisions, col)
+		}
+	}
+	require.Len(t, lpCollisions, 1)
+	require.Equal(t, vault.Raw(), lpCollisions[0].Key)
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+var (
+	ErrBuilderNotFound = errors.New("builder not found")
+	ErrBuilderInactive = errors.New("builder is inactive")
+)
+
+// BuilderChange reports a builder whose Active flag flipped between two
+// applied configs. A builder added active has an inactive Previous and one
+// removed while active an inactive Current, both holding only the address.
+type BuilderChange struct {
+	Previous types.Builder
+	Current  types.Builder
+}
+
+func describeBuilder(b *types.Builder) string {
+	return "builder " + b.Builder
+}
+
+// builderChanges lists the builders whose Active flag differs between the
+// indexes, a missing builder counting as inactive, ordered by address.
+func builderChanges(previous, current map[string]*types.Builder) []BuilderChange {
+	var changes []BuilderChange
+	for key, b := range current {
+		p, ok := previous[key]
+		if !ok {
+			p = &types.Builder{Builder: b.Builder}
+		}
+		if p.Active != b.Active {
+			changes = append(changes, BuilderChange{Previous: *p, Current: *b})
+		}
+	}
+	for key, p := range previous {
+		if _, ok := current[key]; !ok && p.Active {
+			changes = append(changes, BuilderChange{Previous: *p, Current: types.Builder{Builder: p.Builder}})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Current.Builder < changes[j].Current.Builder })
+	return changes
+}
+
+// dispatchBuilderChanges calls the handler with changes in the background,
+// after the changes of the previous refreshes were handled.
+func (c *configDiscovery) dispatchBuilderChanges(changes []BuilderChange) {
+	if c.builderChangeHandler == nil || len(changes) == 0 {
+		return
+	}
+	previous, done := c.builderChangesDone, make(chan struct{})
+	c.builderChangesDone = done
+	go func() {
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		for _, change := range changes {
+			c.builderChangeHandler(change)
+		}
+	}()
+}
+
+func (c *configDiscovery) HasBuilderByAddress(addr string) bool {
+	_, ok := c.BuildersMapByAddress[addressKey(addr)]
+	return ok
+}
+
+// GetBuilderByAddress finds a builder by any representation of its address.
+func (c *configDiscovery) GetBuilderByAddress(addr string) *types.Builder {
+	return c.BuildersMapByAddress[addressKey(addr)]
+}
+
+func (c *configDiscovery) GetActiveBuilders() []types.Builder {
+	var active []types.Builder
+	for _, b := range c.GetBuilders() {
+		if b.Active {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// ComputeBuilderFee splits base according to the rates of the active builder
+// at addr.
+func (c *configDiscovery) ComputeBuilderFee(addr string, base types.Decimal) (*types.BuilderFee, error) {
+	b := c.GetBuilderByAddress(addr)
+	if b == nil {
+		return nil, errors.Wrapf(ErrBuilderNotFound, "%s", addr)
+	}
+	if !b.Active {
+		return nil, errors.Wrapf(ErrBuilderInactive, "%s", addr)
+	}
+	fee, err := b.ApplyFee(base)
+	if err != nil {
+		return nil, err
+	}
+	return &fee, nil
+}
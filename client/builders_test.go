@@ -0,0 +1,136 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+const (
+	builderRaw        = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+	builderBounceable = "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF"
+	builderPlain      = "UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA"
+)
+
+func setBuilders(s *testServer, composedAt string, builders ...types.Builder) {
+	cfg := testAppConfig(composedAt)
+	cfg.Builders = builders
+	s.set("", cfg)
+}
+
+func TestGetBuilderByAddress(t *testing.T) {
+	s := newTestServer(t)
+	setBuilders(s, "2024-01-01T00:00:00Z",
+		types.Builder{Builder: builderBounceable, Rebate: "0.3", Discount: "0.1", Active: true},
+		types.Builder{Builder: "legacy-builder", Rebate: "0.5", Discount: "0"},
+	)
+	c := newTestClient(t, s)
+
+	for _, addr := range []string{builderRaw, builderBounceable, builderPlain} {
+		require.True(t, c.HasBuilderByAddress(addr), addr)
+		require.Equal(t, builderBounceable, c.GetBuilderByAddress(addr).Builder, addr)
+	}
+	require.Equal(t, "legacy-builder", c.GetBuilderByAddress("legacy-builder").Builder)
+	require.Nil(t, c.GetBuilderByAddress("0:0000000000000000000000000000000000000000000000000000000000000000"))
+
+	active := c.GetActiveBuilders()
+	require.Len(t, active, 1)
+	require.Equal(t, builderBounceable, active[0].Builder)
+}
+
+func TestComputeBuilderFee(t *testing.T) {
+	s := newTestServer(t)
+	setBuilders(s, "2024-01-01T00:00:00Z",
+		types.Builder{Builder: builderBounceable, Rebate: "0.3", Discount: "0.1", Active: true},
+		types.Builder{Builder: "inactive", Rebate: "0.5", Discount: "0"},
+	)
+	c := newTestClient(t, s)
+
+	fee, err := c.ComputeBuilderFee(builderRaw, types.MustParseDecimal("10"))
+	require.NoError(t, err)
+	require.Equal(t, "9.0", fee.Fee.String())
+	require.Equal(t, "2.70", fee.Rebate.String())
+
+	_, err = c.ComputeBuilderFee("inactive", types.MustParseDecimal("10"))
+	require.True(t, errors.Is(err, ErrBuilderInactive))
+	_, err = c.ComputeBuilderFee("missing", types.MustParseDecimal("10"))
+	require.True(t, errors.Is(err, ErrBuilderNotFound))
+}
+
+func receiveBuilderChanges(t *testing.T, ch <-chan BuilderChange, n int) []BuilderChange {
+	var changes []BuilderChange
+	for len(changes) < n {
+		select {
+		case change := <-ch:
+			changes = append(changes, change)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d builder changes", len(changes), n)
+		}
+	}
+	return changes
+}
+
+func TestBuilderChangeHandler(t *testing.T) {
+	s := newTestServer(t)
+	setBuilders(s, "2024-01-01T00:00:00Z",
+		types.Builder{Builder: builderBounceable, Active: true},
+		types.Builder{Builder: "steady", Active: true},
+		types.Builder{Builder: "removed", Active: true},
+		types.Builder{Builder: "removed-inactive"},
+	)
+	ch := make(chan BuilderChange, 10)
+	c := newTestClient(t, s, WithBuilderChangeHandler(func(change BuilderChange) { ch <- change }))
+
+	// The same builder now spelled in raw form, deactivated, plus new ones.
+	setBuilders(s, "2024-01-02T00:00:00Z",
+		types.Builder{Builder: builderRaw, Active: false},
+		types.Builder{Builder: "steady", Active: true},
+		types.Builder{Builder: "new", Active: true},
+		types.Builder{Builder: "new-inactive"},
+	)
+	require.NoError(t, c.FetchConfig())
+	require.Equal(t, []BuilderChange{{
+		Previous: types.Builder{Builder: builderBounceable, Active: true},
+		Current:  types.Builder{Builder: builderRaw, Active: false},
+	}, {
+		Previous: types.Builder{Builder: "new"},
+		Current:  types.Builder{Builder: "new", Active: true},
+	}, {
+		Previous: types.Builder{Builder: "removed", Active: true},
+		Current:  types.Builder{Builder: "removed"},
+	}}, receiveBuilderChanges(t, ch, 3))
+	select {
+	case change := <-ch:
+		t.Fatalf("unexpected change %+v", change)
+	default:
+	}
+}
+
+func TestBuilderChangeHandlerDoesNotBlockRefreshes(t *testing.T) {
+	s := newTestServer(t)
+	setBuilders(s, "2024-01-01T00:00:00Z", types.Builder{Builder: "b"})
+	release := make(chan struct{})
+	ch := make(chan BuilderChange, 10)
+	c := newTestClient(t, s, WithBuilderChangeHandler(func(change BuilderChange) {
+		<-release
+		ch <- change
+	}))
+
+	for i, active := range []bool{true, false, true} {
+		setBuilders(s, fmt.Sprintf("2024-01-0%dT00:00:00Z", i+2), types.Builder{Builder: "b", Active: active})
+		require.NoError(t, c.FetchConfig())
+	}
+	require.True(t, c.GetBuilderByAddress("b").Active)
+
+	// Changes are handled in refresh order once the handler is unblocked.
+	close(release)
+	var active []bool
+	for _, change := range receiveBuilderChanges(t, ch, 3) {
+		active = append(active, change.Current.Active)
+	}
+	require.Equal(t, []bool{true, false, true}, active)
+}
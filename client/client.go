@@ -1,26 +1,43 @@
 package client
 
 import (
+	"encoding/json"
 	"math/big"
-	"strconv"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/storm-trade/config-discovery-client/request"
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/signature"
 	"github.com/storm-trade/config-discovery-client/types"
-	"golang.org/x/exp/maps"
+	"github.com/storm-trade/config-discovery-client/validation"
 )
 
 type ConfigDiscovery interface {
 	ListenUpdates() error
 	GetConfig() *types.AppConfig
 	GetBuilders() []types.Builder
+	HasBuilderByAddress(addr string) bool
+	GetBuilderByAddress(addr string) *types.Builder
+	GetActiveBuilders() []types.Builder
+	ComputeBuilderFee(addr string, base types.Decimal) (*types.BuilderFee, error)
 	GetAssets() []*types.Asset
 	GetAssetConfigs() []*types.AssetConfig
 	GetSchedules() map[string]*types.AssetSchedule
 	GetScheduleType(name string) types.ScheduleType
 	IsScheduleEffective(name string) bool
+	IsMarketOpen(name string, t time.Time) (bool, error)
+	CurrentSession(name string, t time.Time) (*schedule.Session, error)
+	NextOpen(name string, t time.Time) (time.Time, error)
+	NextClose(name string, t time.Time) (time.Time, error)
+	TimeUntilClose(name string, t time.Time) (time.Duration, error)
+	GetCalendar(name string, from, to time.Time) ([]schedule.CalendarSession, error)
+	GetResolvedSchedule(name string) *types.AssetSchedule
+	GetResolvedSchedules() map[string]*types.AssetSchedule
+	GetScheduleMismatches() []schedule.Mismatch
 	HasMarketByAddress(address string) bool
 	GetMarketByAddress(address string) *types.Market
 	HasPrelaunchMarketByAddress(address string) bool
@@ -37,6 +54,9 @@ type ConfigDiscovery interface {
 	GetAssetByName(name string) *types.Asset
 	HasCollateralAssetByName(name string) bool
 	GetCollateralAssetByName(name string) *types.CollateralAsset
+	GetCollateralAsset(key string) *types.CollateralAsset
+	FormatCollateralAmount(key string, onchain *big.Int, f types.DecimalFormat) (string, error)
+	ParseCollateralAmount(key, amount string) (*big.Int, error)
 	HasVaultByCollateralAssetId(assetId string) bool
 	GetVaultByCollateralAssetId(assetId string) *types.Vault
 	HasVaultByCollateralAssetName(name string) bool
@@ -47,26 +67,65 @@ type ConfigDiscovery interface {
 	GetAssetConfigByIndex(index int) *types.AssetConfig
 	GetAssetConfigsByProvider(name string) []*types.AssetConfig
 	IsLazer(address string) bool
+	GetOracleClass(provider string) OracleClass
+	GetAssetConfigsByOracleClass(class OracleClass) []*types.AssetConfig
+	GetOracleRoute(assetName string) []OracleRoute
 	GetVPIHistory(name string) (map[int64]types.VPIParamsParsed, bool)
 	GetVPIParamsAtTimestamp(name string, ts int64) (*types.VPIParamsParsed, bool)
+	LookupVPIParams(name string, ts int64) (*types.VPIParamsParsed, error)
+	GetVPIParamsInRange(name string, from, to int64) ([]types.VPIParamsParsed, bool)
+	GetVPIChangesSince(name string, ts int64) ([]types.VPIParamsParsed, bool)
+	GetLatestVPI(name string) (*types.VPIParamsParsed, bool)
+	GetCurrentVPI(name string) (*types.VPIParamsParsed, bool)
+	GetVPIHistoryStats() VPIHistoryStats
+	GetValidationWarnings() []validation.Violation
+	GetIntegrityReport() *validation.IntegrityReport
+	GetIndexCollisions() []Collision
+	GetSnapshot() *types.Snapshot
+	AllowNextRollback()
 	UpdatesChannel() <-chan *types.AppConfig
 }
 
-func strToBigInt(str string) (*big.Int, bool) {
-	n := new(big.Int)
-	return n.SetString(str, 10)
-}
-
 type configDiscovery struct {
 	cfgUri        string
+	verifier      *signature.Verifier
+	validators    []validation.Validator
+	noDefaultVals bool
+	// legacyBaseAssetIndex also keys MarketsMapByAddress by each market's base asset.
+	legacyBaseAssetIndex bool
+	collisionPolicy      CollisionPolicy
+	allowOlderConfigs    bool
+	allowNextRollback    atomic.Bool
+	metrics              Metrics
+	clock                schedule.Clock
+	notifier             *schedule.Notifier
+	eventHandler         func(schedule.Event)
+	builderChangeHandler func(BuilderChange)
+	builderChangesDone   chan struct{}
+	oracleClasses        map[string]OracleClass
+	fullVPIHistory       bool
+	vpiRetention         VPIRetention
+	vpiRetainedFrom      map[string]int64
+	vpiSkipped           []vpiEntryKey
+	// vpiListed holds the assets listed when the known vpi history was fetched.
+	vpiListed map[string]bool
+
 	Updates       chan *types.AppConfig
 	LastUpdatedAt *string
 	Config        *types.AppConfig
 	Assets        []*types.Asset
 	AssetConfigs  []*types.AssetConfig
 	Schedules     map[string]*types.AssetSchedule
-
-	VPIHistory map[string]map[int64]types.VPIParamsParsed
+	Snapshot      *types.Snapshot
+
+	ValidationWarnings []validation.Violation
+	IndexCollisions    []Collision
+	ResolvedSchedules  map[string]*types.AssetSchedule
+	ParsedSchedules    map[string]*schedule.Schedule
+	ScheduleErrors     map[string]error
+	VPIHistory         map[string]map[int64]types.VPIParamsParsed
+	VPIHistorySorted   map[string][]types.VPIParamsParsed
+	CurrentVPI         map[string]types.VPIParamsParsed
 	// Maps
 	VaultsMapByAddress               map[string]*types.Vault
 	VaultsMapByCollateralAssetName   map[string]*types.Vault
@@ -78,19 +137,31 @@ type configDiscovery struct {
 	AssetsMapByName                  map[string]*types.Asset
 	AssetsMapByIndex                 map[int]*types.Asset
 	CollateralAssetsMapByName        map[string]*types.CollateralAsset
+	CollateralAssetsMapByAssetId     map[string]*types.CollateralAsset
+	BuildersMapByAddress             map[string]*types.Builder
 	AssetConfigsMapByName            map[string]*types.AssetConfig
 	AssetConfigsMapByIndex           map[int]*types.AssetConfig
 	AssetConfigsMapByProvider        map[string][]*types.AssetConfig
+	AssetConfigsMapByOracleClass     map[OracleClass][]*types.AssetConfig
 	LazerAssetsMap                   map[string]bool
 }
 
+// Opt configures the client. New calls it with a config owned by the client
+// being built, which the options of this package use to find the client.
 type Opt func(config *types.AppConfig)
 
 func New(configUrl string, opt ...Opt) (ConfigDiscovery, error) {
-	cfg := &configDiscovery{cfgUri: configUrl, Updates: make(chan *types.AppConfig)}
+	cfg := &configDiscovery{cfgUri: configUrl, metrics: nopMetrics{}, clock: schedule.SystemClock, oracleClasses: DefaultOracleClasses(), Updates: make(chan *types.AppConfig)}
 
+	seed := new(types.AppConfig)
+	building.Store(seed, cfg)
 	for _, o := range opt {
-		o(cfg.Config)
+		o(seed)
+	}
+	building.Delete(seed)
+
+	if cfg.eventHandler != nil {
+		cfg.notifier = schedule.NewNotifier(cfg.clock, cfg.eventHandler)
 	}
 
 	if err := cfg.FetchConfig(); err != nil {
@@ -123,87 +194,91 @@ func (c *configDiscovery) ListenUpdates() error {
 }
 
 func (c *configDiscovery) FetchConfig() error {
-	cfg, err := request.Get[types.AppConfig](c.cfgUri)
+	cfg, err := fetch[types.AppConfig](c, c.cfgUri)
 	if err != nil {
 		return errors.Wrap(err, "get app config")
 	}
 
 	if c.LastUpdatedAt == nil || cfg.ComposedAt != *c.LastUpdatedAt {
-		log.Info().Msg("Config is updated, fetching updates")
+		ComposedAt, err := c.checkComposedAt(&cfg)
+		if err != nil {
+			return err
+		}
 
-		c.LastUpdatedAt = &cfg.ComposedAt
+		log.Info().Msg("Config is updated, fetching updates")
 
-		assets, err := request.Get[[]*types.Asset](c.cfgUri + "/assets")
+		assets, err := fetch[[]*types.Asset](c, c.cfgUri+"/assets")
 		if err != nil {
 			return errors.Wrap(err, "fetch assets list")
 		}
 
-		schedule, err := request.Get[types.AssetsSchedule](c.cfgUri + "/assets-schedule")
+		assetsSchedule, err := fetch[types.AssetsSchedule](c, c.cfgUri+"/assets-schedule")
 		if err != nil {
 			return errors.Wrap(err, "fetch assets schedule config")
 		}
 
-		conf, err := request.Get[[]*types.AssetConfig](c.cfgUri + "/assets-config")
+		conf, err := fetch[[]*types.AssetConfig](c, c.cfgUri+"/assets-config")
 		if err != nil {
 			return errors.Wrap(err, "fetch assets config")
 		}
 
-		history, err := request.Get[map[string]map[string]types.VPIParams](c.cfgUri + "/vpi-history")
+		listed := make(map[string]bool, len(assets))
+		for _, a := range assets {
+			listed[a.Name] = true
+		}
+		vpiHistory, err := c.fetchVPIHistory(listed)
 		if err != nil {
-			return errors.Wrap(err, "fetch vpi history")
+			return err
 		}
 
 		Config := &cfg
 		Assets := assets
 		AssetConfigs := conf
-		Schedules := schedule.Schedules
-		VPIHistory := make(map[string]map[int64]types.VPIParamsParsed)
-		for name, h := range history {
-			VPIHistory[name] = make(map[int64]types.VPIParamsParsed)
-			for ts, params := range h {
-				if params.MarketDepthLong == "" || params.MarketDepthShort == "" {
-					continue
-				}
-				timestamp, err := strconv.ParseInt(ts, 10, 64)
-				if err != nil {
-					return errors.Wrap(err, "parse vpi timestamp")
-				}
-				marketDepthLong, ok := strToBigInt(params.MarketDepthLong)
-				if !ok {
-					return errors.New("parse vpi marketDepthLong")
-				}
-				marketDepthShort, ok := strToBigInt(params.MarketDepthShort)
-				if !ok {
-					return errors.New("parse vpi marketDepthShort")
-				}
-				spread, ok := strToBigInt(params.Spread)
-				if !ok {
-					return errors.New("parse vpi spread")
-				}
-				k, ok := strToBigInt(params.K)
-				if !ok {
-					return errors.New("parse vpi k")
-				}
-				VPIHistory[name][timestamp] = types.VPIParamsParsed{
-					Timestamp:        timestamp,
-					MarketDepthLong:  marketDepthLong,
-					MarketDepthShort: marketDepthShort,
-					Spread:           spread,
-					K:                k,
-				}
+		Schedules := assetsSchedule.Schedules
+		VPIHistory := vpiHistory.entries
+		VPIHistorySorted := sortVPIHistory(VPIHistory)
+		CurrentVPI, CurrentVPIWarnings := parseCurrentVPI(AssetConfigs, VPIHistorySorted)
+
+		Snapshot := &types.Snapshot{
+			ComposedAt:   ComposedAt,
+			Config:       Config,
+			Assets:       Assets,
+			AssetConfigs: AssetConfigs,
+			Schedules:    Schedules,
+			VPIHistory:   VPIHistory,
+			CurrentVPI:   CurrentVPI,
+		}
+
+		ValidationWarnings, err := validation.Run(Snapshot, c.validatorChain()...)
+		if err != nil {
+			log.Error().Err(err).Msg("config rejected by validation")
+			c.metrics.ConfigRejected(RejectReasonValidation)
+			return errors.Wrap(err, "validate config")
+		}
+		ValidationWarnings = append(append(vpiHistory.warnings(), CurrentVPIWarnings...), ValidationWarnings...)
+		for _, w := range ValidationWarnings {
+			if w.Rule == validation.RuleDuplicateKeys {
+				// Every duplicate key is logged below as an index collision.
+				continue
 			}
+			log.Warn().Str("rule", w.Rule).Str("subject", w.Subject).Msg(w.Message)
 		}
 
+		ResolvedSchedules := schedule.ResolveAll(Schedules, AssetConfigs)
+		ParsedSchedules, ScheduleErrors := parseSchedules(ResolvedSchedules)
+
+		var IndexCollisions []Collision
+
 		VaultsMapByAddress := make(map[string]*types.Vault)
 		VaultsMapByCollateralAssetName := make(map[string]*types.Vault)
 		VaultsMapByCollateralAssetId := make(map[string]*types.Vault)
 		VaultsMapByLpJettonMasterAddress := make(map[string]*types.Vault)
 
 		for _, v := range Config.Vaults {
-			VaultsMapByAddress[v.VaultAddress] = &v
-			VaultsMapByCollateralAssetName[v.Asset.Name] = &v
-			VaultsMapByCollateralAssetId[v.Asset.AssetId] = &v
-			VaultsMapByLpJettonMasterAddress[v.LpJettonMaster] = &v
+			index(&IndexCollisions, "vaults by address", VaultsMapByAddress, addressKey(v.VaultAddress), &v, describeVault)
+			index(&IndexCollisions, "vaults by collateral asset name", VaultsMapByCollateralAssetName, v.Asset.Name, &v, describeVault)
+			index(&IndexCollisions, "vaults by collateral asset id", VaultsMapByCollateralAssetId, v.Asset.AssetId, &v, describeVault)
+			index(&IndexCollisions, "vaults by lp jetton master", VaultsMapByLpJettonMasterAddress, addressKey(v.LpJettonMaster), &v, describeVault)
 		}
 
 		MarketsMapByAddress := make(map[string]*types.Market)
@@ -211,10 +286,9 @@ func (c *configDiscovery) FetchConfig() error {
 		MarketsMapByBaseAssetName := make(map[string][]types.Market)
 
 		for _, m := range Config.OpenedMarkets {
-			MarketsMapByAddress[m.Address] = &m
-			MarketsMapByAddress[m.BaseAsset] = &m
+			index(&IndexCollisions, "markets by address", MarketsMapByAddress, addressKey(m.Address), &m, describeMarket)
 			if m.Type == "prelaunch" {
-				PrelaunchMarketsMapByAddress[m.Address] = &m
+				index(&IndexCollisions, "prelaunch markets by address", PrelaunchMarketsMapByAddress, addressKey(m.Address), &m, describeMarket)
 			}
 			if MarketsMapByBaseAssetName[m.BaseAsset] == nil {
 				MarketsMapByBaseAssetName[m.BaseAsset] = make([]types.Market, 0)
@@ -222,31 +296,54 @@ func (c *configDiscovery) FetchConfig() error {
 			MarketsMapByBaseAssetName[m.BaseAsset] = append(MarketsMapByBaseAssetName[m.BaseAsset], m)
 		}
 
+		// Base asset names go in after every real address so that an address
+		// always wins over a base asset name spelled the same way.
+		if c.legacyBaseAssetIndex {
+			for _, m := range Config.OpenedMarkets {
+				index(&IndexCollisions, "markets by base asset (legacy)", MarketsMapByAddress, m.BaseAsset, &m, describeMarket)
+			}
+		}
+
+		BuildersMapByAddress := make(map[string]*types.Builder)
+
+		for _, b := range Config.Builders {
+			index(&IndexCollisions, "builders by address", BuildersMapByAddress, addressKey(b.Builder), &b, describeBuilder)
+		}
+
 		CollateralAssetsMapByName := make(map[string]*types.CollateralAsset)
+		CollateralAssetsMapByAssetId := make(map[string]*types.CollateralAsset)
 
 		for _, a := range Config.CollateralAssets {
-			CollateralAssetsMapByName[a.Name] = &a
+			index(&IndexCollisions, "collateral assets by name", CollateralAssetsMapByName, a.Name, &a, describeCollateralAsset)
+			index(&IndexCollisions, "collateral assets by asset id", CollateralAssetsMapByAssetId, a.AssetId, &a, describeCollateralAsset)
 		}
 
 		AssetsMapByName := make(map[string]*types.Asset)
 		AssetsMapByIndex := make(map[int]*types.Asset)
 
 		for _, a := range Assets {
-			AssetsMapByName[a.Name] = a
-			AssetsMapByIndex[a.Index] = a
+			index(&IndexCollisions, "assets by name", AssetsMapByName, a.Name, a, describeAsset)
+			index(&IndexCollisions, "assets by index", AssetsMapByIndex, a.Index, a, describeAsset)
 		}
 
 		AssetConfigsMapByProvider := make(map[string][]*types.AssetConfig)
 		AssetConfigsMapByName := make(map[string]*types.AssetConfig)
 		AssetConfigsMapByIndex := make(map[int]*types.AssetConfig)
+		AssetConfigsMapByOracleClass := make(map[OracleClass][]*types.AssetConfig)
 		LazerAssetsMap := make(map[string]bool)
 		for _, a := range AssetConfigs {
-			AssetConfigsMapByName[a.Name] = a
-			AssetConfigsMapByIndex[a.Index] = a
+			index(&IndexCollisions, "asset configs by name", AssetConfigsMapByName, a.Name, a, describeAssetConfig)
+			index(&IndexCollisions, "asset configs by index", AssetConfigsMapByIndex, a.Index, a, describeAssetConfig)
+			classes := make(map[OracleClass]bool)
 			for _, o := range a.Oracles {
-				if o.Provider == "pyth-lazer" || o.Provider == "stork-fast" || o.Provider == "fake" || o.Provider == "stork-custom" {
+				class := c.oracleClasses[o.Provider]
+				if class == OracleClassLazer {
 					LazerAssetsMap[a.Name] = true
 				}
+				if class != OracleClassNone && !classes[class] {
+					classes[class] = true
+					AssetConfigsMapByOracleClass[class] = append(AssetConfigsMapByOracleClass[class], a)
+				}
 				if AssetConfigsMapByProvider[o.Provider] == nil {
 					AssetConfigsMapByProvider[o.Provider] = make([]*types.AssetConfig, 0)
 				}
@@ -254,12 +351,39 @@ func (c *configDiscovery) FetchConfig() error {
 			}
 		}
 
+		for _, collision := range IndexCollisions {
+			log.Warn().Str("index", collision.Index).Str("key", collision.Key).Msg(collision.String())
+		}
+		if len(IndexCollisions) > 0 && c.collisionPolicy == CollisionFatal {
+			c.metrics.ConfigRejected(RejectReasonCollision)
+			return &CollisionError{Collisions: IndexCollisions}
+		}
+
+		// The first config has nothing to compare the builders with.
+		var BuilderChanges []BuilderChange
+		if c.LastUpdatedAt != nil {
+			BuilderChanges = builderChanges(c.BuildersMapByAddress, BuildersMapByAddress)
+		}
+
 		{
+			c.LastUpdatedAt = &cfg.ComposedAt
 			c.Config = Config
 			c.Assets = Assets
 			c.AssetConfigs = AssetConfigs
 			c.Schedules = Schedules
+			c.ResolvedSchedules = ResolvedSchedules
+			c.ParsedSchedules = ParsedSchedules
+			c.ScheduleErrors = ScheduleErrors
+			c.Snapshot = Snapshot
+			c.allowNextRollback.Store(false)
+			c.ValidationWarnings = ValidationWarnings
+			c.IndexCollisions = IndexCollisions
 			c.VPIHistory = VPIHistory
+			c.VPIHistorySorted = VPIHistorySorted
+			c.CurrentVPI = CurrentVPI
+			c.vpiSkipped = vpiHistory.skipped
+			c.vpiRetainedFrom = vpiHistory.retainedFrom
+			c.vpiListed = listed
 			c.VaultsMapByAddress = VaultsMapByAddress
 			c.VaultsMapByCollateralAssetName = VaultsMapByCollateralAssetName
 			c.VaultsMapByCollateralAssetId = VaultsMapByCollateralAssetId
@@ -270,20 +394,91 @@ func (c *configDiscovery) FetchConfig() error {
 			c.AssetsMapByName = AssetsMapByName
 			c.AssetsMapByIndex = AssetsMapByIndex
 			c.CollateralAssetsMapByName = CollateralAssetsMapByName
+			c.CollateralAssetsMapByAssetId = CollateralAssetsMapByAssetId
+			c.BuildersMapByAddress = BuildersMapByAddress
 			c.AssetConfigsMapByName = AssetConfigsMapByName
 			c.AssetConfigsMapByIndex = AssetConfigsMapByIndex
 			c.AssetConfigsMapByProvider = AssetConfigsMapByProvider
+			c.AssetConfigsMapByOracleClass = AssetConfigsMapByOracleClass
 			c.LazerAssetsMap = LazerAssetsMap
 		}
 
+		if c.notifier != nil {
+			c.notifier.Reset(effectiveSchedules(ResolvedSchedules, ParsedSchedules))
+		}
+
+		c.dispatchBuilderChanges(BuilderChanges)
+
 		go func() {
-			c.Updates <- c.Config
+			c.Updates <- Config
 		}()
 	}
 
 	return nil
 }
 
+// fetch downloads a resource and, when a verifier is configured, checks its
+// detached signature before decoding so that a refused resource never reaches
+// the indexes and the last good snapshot stays in place.
+func fetch[T any](c *configDiscovery, uri string) (T, error) {
+	var result T
+
+	resp, err := c.fetchResponse(uri)
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return result, errors.Wrap(err, "unmarshal json")
+	}
+
+	return result, nil
+}
+
+func (c *configDiscovery) fetchResponse(uri string) (*request.Response, error) {
+	resp, err := request.Fetch(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.verifier != nil {
+		if err := c.verify(uri, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *configDiscovery) verify(uri string, resp *request.Response) error {
+	sig := resp.Header.Get(signature.Header)
+	if sig == "" {
+		companion, err := request.Fetch(signature.CompanionURI(uri))
+		var statusErr *request.StatusError
+		if err != nil && !(errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound) {
+			return errors.Wrap(err, "fetch signature")
+		}
+		if companion != nil {
+			sig = string(companion.Body)
+		}
+	}
+
+	if err := c.verifier.Verify(uri, resp.Body, sig); err != nil {
+		log.Error().Err(err).Str("resource", uri).Msg("config rejected by signature verification")
+		c.metrics.ConfigRejected(RejectReasonSignature)
+		return err
+	}
+
+	return nil
+}
+
+func (c *configDiscovery) validatorChain() []validation.Validator {
+	if c.noDefaultVals {
+		return c.validators
+	}
+	return append(validation.Default(), c.validators...)
+}
+
 func (c *configDiscovery) UpdatesChannel() <-chan *types.AppConfig {
 	return c.Updates
 }
@@ -292,6 +487,10 @@ func (c *configDiscovery) GetConfig() *types.AppConfig {
 	return c.Config
 }
 
+func (c *configDiscovery) GetSnapshot() *types.Snapshot {
+	return c.Snapshot
+}
+
 func (c *configDiscovery) GetBuilders() []types.Builder {
 	if c.Config == nil {
 		return nil
@@ -311,8 +510,10 @@ func (c *configDiscovery) GetSchedules() map[string]*types.AssetSchedule {
 	return c.Schedules
 }
 
+// GetScheduleType returns the type of the resolved schedule of an asset, see
+// GetResolvedSchedule.
 func (c *configDiscovery) GetScheduleType(name string) types.ScheduleType {
-	return c.Schedules[name].GetScheduleType()
+	return c.ResolvedSchedules[name].GetScheduleType()
 }
 
 func (c *configDiscovery) IsScheduleEffective(name string) bool {
@@ -320,23 +521,31 @@ func (c *configDiscovery) IsScheduleEffective(name string) bool {
 }
 
 func (c *configDiscovery) HasMarketByAddress(address string) bool {
-	return c.MarketsMapByAddress[address] != nil
+	return c.MarketsMapByAddress[addressKey(address)] != nil
 }
 
 func (c *configDiscovery) GetMarketByAddress(address string) *types.Market {
-	return c.MarketsMapByAddress[address]
+	return c.MarketsMapByAddress[addressKey(address)]
 }
 
 func (c *configDiscovery) HasPrelaunchMarketByAddress(address string) bool {
-	return c.PrelaunchMarketsMapByAddress[address] != nil
+	return c.PrelaunchMarketsMapByAddress[addressKey(address)] != nil
 }
 
 func (c *configDiscovery) GetPrelaunchMarketByAddress(address string) *types.Market {
-	return c.PrelaunchMarketsMapByAddress[address]
+	return c.PrelaunchMarketsMapByAddress[addressKey(address)]
 }
 
+// GetMarketsAddresses returns the addresses as the service spelled them.
 func (c *configDiscovery) GetMarketsAddresses() []string {
-	return maps.Keys(c.MarketsMapByAddress)
+	addresses := make([]string, 0, len(c.MarketsMapByAddress))
+	for key, m := range c.MarketsMapByAddress {
+		if key == addressKey(m.Address) {
+			key = m.Address
+		}
+		addresses = append(addresses, key)
+	}
+	return addresses
 }
 
 func (c *configDiscovery) GetMarketsByAssetName(name string) []types.Market {
@@ -344,19 +553,19 @@ func (c *configDiscovery) GetMarketsByAssetName(name string) []types.Market {
 }
 
 func (c *configDiscovery) HasVaultByAddress(address string) bool {
-	return c.VaultsMapByAddress[address] != nil
+	return c.VaultsMapByAddress[addressKey(address)] != nil
 }
 
 func (c *configDiscovery) GetVaultByAddress(address string) *types.Vault {
-	return c.VaultsMapByAddress[address]
+	return c.VaultsMapByAddress[addressKey(address)]
 }
 
 func (c *configDiscovery) HasVaultByLpJettonMasterAddress(address string) bool {
-	return c.VaultsMapByLpJettonMasterAddress[address] != nil
+	return c.VaultsMapByLpJettonMasterAddress[addressKey(address)] != nil
 }
 
 func (c *configDiscovery) GetVaultByLpJettonMasterAddress(address string) *types.Vault {
-	return c.VaultsMapByLpJettonMasterAddress[address]
+	return c.VaultsMapByLpJettonMasterAddress[addressKey(address)]
 }
 
 func (c *configDiscovery) HasAssetByIndex(index int) bool {
@@ -425,22 +634,20 @@ func (c *configDiscovery) GetVPIHistory(name string) (map[int64]types.VPIParamsP
 }
 
 func (c *configDiscovery) GetVPIParamsAtTimestamp(name string, ts int64) (*types.VPIParamsParsed, bool) {
-	i, ok := c.VPIHistory[name]
-	if !ok {
-		return nil, false
-	}
-	var bestTS int64 = -1
-	var bestParams types.VPIParamsParsed
-	for timestamp, params := range i {
-		if timestamp <= ts && timestamp > bestTS {
-			bestTS = timestamp
-			bestParams = params
-		}
-	}
-	if bestTS == -1 {
-		return nil, false
-	}
-	return &bestParams, true
+	params, err := c.LookupVPIParams(name, ts)
+	return params, err == nil
+}
+
+func (c *configDiscovery) GetValidationWarnings() []validation.Violation {
+	return c.ValidationWarnings
+}
+
+func (c *configDiscovery) GetIntegrityReport() *validation.IntegrityReport {
+	return validation.BuildIntegrityReport(c.Snapshot)
+}
+
+func (c *configDiscovery) GetIndexCollisions() []Collision {
+	return c.IndexCollisions
 }
 
 func (c *configDiscovery) IsLazer(name string) bool {
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+var ErrUnknownCollateral = errors.New("unknown collateral asset")
+
+// GetCollateralAsset resolves key as a collateral asset name, asset id, vault
+// address or market address, in that order.
+func (c *configDiscovery) GetCollateralAsset(key string) *types.CollateralAsset {
+	if a := c.CollateralAssetsMapByName[key]; a != nil {
+		return a
+	}
+	if a := c.CollateralAssetsMapByAssetId[key]; a != nil {
+		return a
+	}
+	v := c.GetVaultByAddress(key)
+	if v == nil {
+		if m := c.GetMarketByAddress(key); m != nil {
+			v = c.GetVaultByAddress(m.VaultAddress)
+		}
+	}
+	if v == nil {
+		return nil
+	}
+	if a := c.CollateralAssetsMapByName[v.Asset.Name]; a != nil {
+		return a
+	}
+	return &v.Asset
+}
+
+func (c *configDiscovery) FormatCollateralAmount(key string, onchain *big.Int, f types.DecimalFormat) (string, error) {
+	a := c.GetCollateralAsset(key)
+	if a == nil {
+		return "", errors.Wrapf(ErrUnknownCollateral, "%s", key)
+	}
+	return a.FormatAmount(onchain, f), nil
+}
+
+// ParseCollateralAmount converts a human amount into the on-chain integer,
+// failing rather than rounding. Use GetCollateralAsset(key).RoundOnChain to
+// round instead.
+func (c *configDiscovery) ParseCollateralAmount(key, amount string) (*big.Int, error) {
+	a := c.GetCollateralAsset(key)
+	if a == nil {
+		return nil, errors.Wrapf(ErrUnknownCollateral, "%s", key)
+	}
+	return a.ParseAmount(amount)
+}
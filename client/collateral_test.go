@@ -0,0 +1,37 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestCollateralAmountHelpers(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+
+	for _, key := range []string{"USDT", "usdt-id", "vault-usdt", "market-btc", "market-ltc"} {
+		a := c.GetCollateralAsset(key)
+		require.NotNil(t, a, key)
+		require.Equal(t, "USDT", a.Name, key)
+
+		formatted, err := c.FormatCollateralAmount(key, big.NewInt(1_234_567_890), types.DecimalFormat{ThousandsSeparator: ","})
+		require.NoError(t, err, key)
+		require.Equal(t, "1,234.567890", formatted, key)
+
+		n, err := c.ParseCollateralAmount(key, "1234.56789")
+		require.NoError(t, err, key)
+		require.Equal(t, "1234567890", n.String(), key)
+	}
+
+	require.Nil(t, c.GetCollateralAsset("DOGE"))
+	_, err := c.FormatCollateralAmount("DOGE", big.NewInt(1), types.DecimalFormat{})
+	require.True(t, errors.Is(err, ErrUnknownCollateral))
+	_, err = c.ParseCollateralAmount("DOGE", "1")
+	require.True(t, errors.Is(err, ErrUnknownCollateral))
+	_, err = c.ParseCollateralAmount("USDT", "0.0000001")
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/storm-trade/config-discovery-client/address"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// addressKey is the key of every address index: the raw form of a TON address
+// in any representation, or the value unchanged when it isn't one.
+func addressKey(s string) string {
+	return address.Canonical(s)
+}
+
+type CollisionPolicy int
+
+const (
+	// CollisionWarn logs collisions and applies the snapshot.
+	CollisionWarn CollisionPolicy = iota
+	// CollisionFatal rejects any snapshot whose indexes collide.
+	CollisionFatal
+)
+
+// Collision is a key claimed by more than one entity while building an index.
+// The first entity in response order keeps the key.
+type Collision struct {
+	Index   string `json:"index"`
+	Key     string `json:"key"`
+	Kept    string `json:"kept"`
+	Dropped string `json:"dropped"`
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("%s: key %q kept by %s, dropped %s", c.Index, c.Key, c.Kept, c.Dropped)
+}
+
+type CollisionError struct {
+	Collisions []Collision
+}
+
+func (e *CollisionError) Error() string {
+	parts := make([]string, 0, len(e.Collisions))
+	for _, c := range e.Collisions {
+		parts = append(parts, c.String())
+	}
+	return fmt.Sprintf("%d index collisions: %s", len(e.Collisions), strings.Join(parts, "; "))
+}
+
+// index stores value under key unless the key is already taken, in which case
+// the collision is recorded and the existing entry is kept.
+func index[K comparable, V any](collisions *[]Collision, name string, m map[K]V, key K, value V, describe func(V) string) {
+	if existing, ok := m[key]; ok {
+		*collisions = append(*collisions, Collision{
+			Index:   name,
+			Key:     fmt.Sprint(key),
+			Kept:    describe(existing),
+			Dropped: describe(value),
+		})
+		return
+	}
+	m[key] = value
+}
+
+func describeVault(v *types.Vault) string {
+	return "vault " + v.VaultAddress
+}
+
+func describeMarket(m *types.Market) string {
+	return "market " + m.Address
+}
+
+func describeCollateralAsset(a *types.CollateralAsset) string {
+	return "collateral asset " + a.AssetId
+}
+
+func describeAsset(a *types.Asset) string {
+	return fmt.Sprintf("asset %s#%d", a.Name, a.Index)
+}
+
+func describeAssetConfig(a *types.AssetConfig) string {
+	return fmt.Sprintf("asset config %s#%d", a.Name, a.Index)
+}
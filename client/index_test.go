@@ -0,0 +1,111 @@
+package client
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestMarketAddressIndexExcludesBaseAssets(t *testing.T) {
+	c := newTestClient(t, newTestServer(t))
+
+	require.False(t, c.HasMarketByAddress("BTC"))
+	addresses := c.GetMarketsAddresses()
+	sort.Strings(addresses)
+	require.Equal(t, []string{"market-btc", "market-ltc"}, addresses)
+	require.Empty(t, c.GetIndexCollisions())
+}
+
+func TestLegacyBaseAssetIndexPrefersAddresses(t *testing.T) {
+	s := newTestServer(t)
+	cfg := testAppConfig("2024-01-01T00:00:00Z")
+	cfg.OpenedMarkets[1].BaseAsset = "market-btc"
+	s.set("", cfg)
+
+	c := newTestClient(t, s, WithLegacyBaseAssetAddressIndex(), WithoutDefaultValidators())
+	require.Equal(t, "market-btc", c.GetMarketByAddress("BTC").Address)
+	require.Equal(t, "market-btc", c.GetMarketByAddress("market-btc").Address)
+	require.Equal(t, []Collision{{
+		Index:   "markets by base asset (legacy)",
+		Key:     "market-btc",
+		Kept:    "market market-btc",
+		Dropped: "market market-ltc",
+	}}, c.GetIndexCollisions())
+}
+
+func TestVaultCollisionsKeepFirstVault(t *testing.T) {
+	s := newTestServer(t)
+	cfg := testAppConfig("2024-01-01T00:00:00Z")
+	cfg.Vaults = append(cfg.Vaults, types.Vault{Asset: cfg.Vaults[0].Asset, VaultAddress: "vault-usdt-2", LpJettonMaster: "lp-usdt-2"})
+	s.set("", cfg)
+
+	c := newTestClient(t, s)
+	require.Equal(t, "vault-usdt", c.GetVaultByCollateralAssetName("USDT").VaultAddress)
+	require.Equal(t, "vault-usdt-2", c.GetVaultByAddress("vault-usdt-2").VaultAddress)
+	require.Len(t, c.GetIndexCollisions(), 2)
+}
+
+func TestFatalCollisionPolicyKeepsPreviousSnapshot(t *testing.T) {
+	s := newTestServer(t)
+	metrics := &countingMetrics{}
+	c := newTestClient(t, s, WithCollisionPolicy(CollisionFatal), WithMetrics(metrics))
+
+	cfg := testAppConfig("2024-01-02T00:00:00Z")
+	cfg.Vaults = append(cfg.Vaults, types.Vault{Asset: cfg.Vaults[0].Asset, VaultAddress: "vault-usdt-2", LpJettonMaster: "lp-usdt-2"})
+	s.set("", cfg)
+
+	err := c.FetchConfig()
+	var collisionErr *CollisionError
+	require.True(t, errors.As(err, &collisionErr))
+	require.Len(t, collisionErr.Collisions, 2)
+	require.Equal(t, 1, metrics.rejected[RejectReasonCollision])
+	require.Equal(t, "2024-01-01T00:00:00Z", c.GetConfig().ComposedAt)
+	require.False(t, c.HasVaultByAddress("vault-usdt-2"))
+}
+
+func TestDuplicateKeysFollowCollisionPolicy(t *testing.T) {
+	s := newTestServer(t)
+	cfg := testAppConfig("2024-01-01T00:00:00Z")
+	cfg.OpenedMarkets = append(cfg.OpenedMarkets, types.Market{Name: "BTC/USDT v2", Address: "market-btc", VaultAddress: "vault-usdt"})
+	s.set("", cfg)
+	s.set("/assets", []*types.Asset{{Name: "BTC", Index: 0}, {Name: "BTC", Index: 1}})
+
+	c := newTestClient(t, s)
+	require.Equal(t, "BTC/USDT", c.GetMarketByAddress("market-btc").Name)
+	require.Equal(t, 0, c.GetAssetByName("BTC").Index)
+	require.Len(t, c.GetIndexCollisions(), 2)
+	var rules []string
+	for _, w := range c.GetValidationWarnings() {
+		rules = append(rules, w.Rule+": "+w.Subject)
+	}
+	require.Equal(t, []string{"duplicate-keys: asset name BTC", "duplicate-keys: market address market-btc"}, rules)
+
+	_, err := New(s.uri(), WithCollisionPolicy(CollisionFatal))
+	var collisionErr *CollisionError
+	require.True(t, errors.As(err, &collisionErr))
+}
+
+func TestLookupsKeepUnknownFields(t *testing.T) {
+	s := newTestServer(t)
+	s.setRaw("", []byte(`{"composedAt":"2024-01-01T00:00:00Z",
+		"openedMarkets":[{"name":"BTC/USDT","address":"market-btc","vaultAddress":"vault-usdt","maxLeverage":50}],
+		"liquiditySources":[{"asset":{"name":"USDT"},"vaultAddress":"vault-usdt","lpJettonMaster":"lp-usdt","tvlCap":"1000"}],
+		"assets":[{"name":"USDT"}]}`))
+	s.setRaw("/assets-config", []byte(`[{"index":0,"name":"BTC","listedAt":1700000000}]`))
+	c := newTestClient(t, s)
+
+	leverage, err := types.GetExtraAs[int](c.GetMarketByAddress("market-btc"), "maxLeverage")
+	require.NoError(t, err)
+	require.Equal(t, 50, leverage)
+
+	capacity, err := types.GetExtraAs[string](c.GetVaultByAddress("vault-usdt"), "tvlCap")
+	require.NoError(t, err)
+	require.Equal(t, "1000", capacity)
+
+	listedAt, ok := c.GetAssetConfigByName("BTC").Extra("listedAt")
+	require.True(t, ok)
+	require.Equal(t, "1700000000", string(listedAt))
+}
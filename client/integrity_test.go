@@ -0,0 +1,18 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestIntegrityReportOfCurrentSnapshot(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets", []*types.Asset{{Name: "BTC", Index: 0}, {Name: "LTC", Index: 11}, {Name: "ETH", Index: 1}})
+
+	c := newTestClient(t, s)
+	r := c.GetIntegrityReport()
+	require.Equal(t, []string{"ETH"}, r.AssetsWithoutConfig)
+	require.Empty(t, r.MarketsWithoutVault)
+}
@@ -0,0 +1,18 @@
+package client
+
+const (
+	RejectReasonSignature  = "signature"
+	RejectReasonValidation = "validation"
+	RejectReasonCollision  = "collision"
+	RejectReasonRollback   = "rollback"
+)
+
+// Metrics receives counters from the refresh loop. Implementations must be safe
+// for concurrent use.
+type Metrics interface {
+	ConfigRejected(reason string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ConfigRejected(string) {}
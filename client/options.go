@@ -0,0 +1,129 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/signature"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+)
+
+// building holds the client each New call is configuring, keyed by the config
+// it passes to the options.
+var building sync.Map
+
+// clientOpt makes an Opt that applies apply to the client being built. It
+// does nothing when called outside of New.
+func clientOpt(apply func(c *configDiscovery)) Opt {
+	return func(config *types.AppConfig) {
+		if c, ok := building.Load(config); ok {
+			apply(c.(*configDiscovery))
+		}
+	}
+}
+
+// WithSignatureVerifier refuses every resource whose detached signature does not
+// verify against one of the verifier's trusted keys.
+func WithSignatureVerifier(v *signature.Verifier) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.verifier = v
+	})
+}
+
+func WithMetrics(m Metrics) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		c.metrics = m
+	})
+}
+
+// WithValidators registers additional rules run on every candidate snapshot
+// after the built-in ones.
+func WithValidators(v ...validation.Validator) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.validators = append(c.validators, v...)
+	})
+}
+
+func WithoutDefaultValidators() Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.noDefaultVals = true
+	})
+}
+
+func WithCollisionPolicy(p CollisionPolicy) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.collisionPolicy = p
+	})
+}
+
+// WithLegacyBaseAssetAddressIndex restores the old behaviour of resolving
+// GetMarketByAddress by base asset name as well as by market address.
+func WithLegacyBaseAssetAddressIndex() Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.legacyBaseAssetIndex = true
+	})
+}
+
+// WithAllowOlderConfigs applies every config whose composedAt differs from the
+// current one, as the client did before rollback protection existed.
+func WithAllowOlderConfigs() Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.allowOlderConfigs = true
+	})
+}
+
+// WithClock replaces the wall clock used for schedule events.
+func WithClock(clock schedule.Clock) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.clock = clock
+	})
+}
+
+// WithScheduleEventHandler calls h whenever an asset with an effective schedule
+// opens, closes or enters a holiday. Timers are re-armed on every refresh.
+func WithScheduleEventHandler(h func(schedule.Event)) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.eventHandler = h
+	})
+}
+
+// WithBuilderChangeHandler calls h after each refresh for every builder whose
+// Active flag flipped, added and removed builders counting as inactive on the
+// side they are missing from. h runs in the background, one change at a time
+// in refresh order, so a slow handler doesn't hold up refreshes.
+func WithBuilderChangeHandler(h func(BuilderChange)) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.builderChangeHandler = h
+	})
+}
+
+// WithOracleClasses replaces DefaultOracleClasses, mapping provider names to
+// their class. Providers left out have OracleClassNone.
+func WithOracleClasses(classes map[string]OracleClass) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.oracleClasses = make(map[string]OracleClass, len(classes))
+		for provider, class := range classes {
+			c.oracleClasses[provider] = class
+		}
+	})
+}
+
+// WithFullVPIHistory downloads the whole /vpi-history on every refresh instead
+// of only the entries newer than the known ones.
+func WithFullVPIHistory() Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.fullVPIHistory = true
+	})
+}
+
+// WithVPIRetention bounds the VPI history kept in memory. Lookups before the
+// retained window fail with ErrVPIOutOfRetention.
+func WithVPIRetention(r VPIRetention) Opt {
+	return clientOpt(func(c *configDiscovery) {
+		c.vpiRetention = r
+	})
+}
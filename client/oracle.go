@@ -0,0 +1,90 @@
+package client
+
+import (
+	"sort"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// OracleClass groups oracle providers with the same delivery guarantees.
+type OracleClass string
+
+const (
+	// OracleClassLazer providers push low latency updates. Assets with at
+	// least one of them are reported by IsLazer.
+	OracleClassLazer OracleClass = "lazer"
+	// OracleClassNone is the class of providers missing from the classification.
+	OracleClassNone OracleClass = ""
+)
+
+// DefaultOracleClasses returns the classification used unless
+// WithOracleClasses replaces it.
+func DefaultOracleClasses() map[string]OracleClass {
+	return map[string]OracleClass{
+		"pyth-lazer":   OracleClassLazer,
+		"stork-fast":   OracleClassLazer,
+		"fake":         OracleClassLazer,
+		"stork-custom": OracleClassLazer,
+	}
+}
+
+// GetOracleClass returns the class of an oracle provider.
+func (c *configDiscovery) GetOracleClass(provider string) OracleClass {
+	return c.oracleClasses[provider]
+}
+
+// GetAssetConfigsByOracleClass returns the asset configs with at least one
+// oracle of class, each once, in response order.
+func (c *configDiscovery) GetAssetConfigsByOracleClass(class OracleClass) []*types.AssetConfig {
+	return c.AssetConfigsMapByOracleClass[class]
+}
+
+// OracleRoute is one oracle of an asset with what a price service needs to
+// subscribe to it. Priority, Weight and StalenessThreshold are nil when the
+// service doesn't set them.
+type OracleRoute struct {
+	Provider           string
+	FeedId             types.FeedId
+	Class              OracleClass
+	Priority           *int
+	Weight             *types.Decimal
+	StalenessThreshold *types.Decimal
+}
+
+// GetOracleRoute returns the oracles of an asset config in fallback order:
+// ascending priority, then oracles without one, ties kept in response order.
+// It returns nil for unknown assets.
+func (c *configDiscovery) GetOracleRoute(assetName string) []OracleRoute {
+	a := c.AssetConfigsMapByName[assetName]
+	if a == nil {
+		return nil
+	}
+
+	route := make([]OracleRoute, 0, len(a.Oracles))
+	for _, o := range a.Oracles {
+		route = append(route, OracleRoute{
+			Provider:           o.Provider,
+			FeedId:             o.FeedId,
+			Class:              c.oracleClasses[o.Provider],
+			Priority:           clonePtr(o.Priority),
+			Weight:             clonePtr(o.Weight),
+			StalenessThreshold: clonePtr(o.StalenessThreshold),
+		})
+	}
+	sort.SliceStable(route, func(i, j int) bool {
+		pi, pj := route[i].Priority, route[j].Priority
+		if pi == nil || pj == nil {
+			return pi != nil && pj == nil
+		}
+		return *pi < *pj
+	})
+	return route
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func assetConfigNames(configs []*types.AssetConfig) []string {
+	var names []string
+	for _, a := range configs {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func TestDefaultOracleClasses(t *testing.T) {
+	c := newTestClient(t, newTestServer(t))
+
+	require.Equal(t, OracleClassLazer, c.GetOracleClass("pyth-lazer"))
+	require.Equal(t, OracleClassLazer, c.GetOracleClass("stork-custom"))
+	require.Equal(t, OracleClassNone, c.GetOracleClass("pyth"))
+	require.True(t, c.IsLazer("BTC"))
+	require.False(t, c.IsLazer("LTC"))
+	require.Equal(t, []string{"BTC"}, assetConfigNames(c.GetAssetConfigsByOracleClass(OracleClassLazer)))
+	require.Empty(t, c.GetAssetConfigsByOracleClass(OracleClassNone))
+}
+
+func TestWithOracleClasses(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", Oracles: []types.OracleConfig{{Provider: "pyth-lazer"}, {Provider: "pyth"}}},
+		{Index: 11, Name: "LTC", Oracles: []types.OracleConfig{{Provider: "pyth"}, {Provider: "redstone"}}},
+	})
+	classes := map[string]OracleClass{"pyth": "pull", "redstone": "pull", "new-lazer": OracleClassLazer}
+	c := newTestClient(t, s, WithOracleClasses(classes))
+	classes["pyth"] = OracleClassLazer
+
+	require.Equal(t, OracleClass("pull"), c.GetOracleClass("pyth"))
+	require.Equal(t, OracleClassNone, c.GetOracleClass("pyth-lazer"))
+	require.False(t, c.IsLazer("BTC"))
+	require.Equal(t, []string{"BTC", "LTC"}, assetConfigNames(c.GetAssetConfigsByOracleClass("pull")))
+	require.Empty(t, c.GetAssetConfigsByOracleClass(OracleClassLazer))
+}
+
+func TestGetOracleRoute(t *testing.T) {
+	s := newTestServer(t)
+	s.setRaw("/assets-config", []byte(`[
+		{"index":0,"name":"BTC","oracles":[
+			{"provider":"pyth"},
+			{"provider":"stork-fast","feedId":"BTCUSD","priority":1,"stalenessThreshold":1.5},
+			{"provider":"pyth-lazer","feedId":1,"priority":0,"weight":"0.6"},
+			{"provider":"redstone","feedId":"BTC"}
+		]},
+		{"index":11,"name":"LTC"}
+	]`))
+	c := newTestClient(t, s)
+
+	route := c.GetOracleRoute("BTC")
+	var providers []string
+	for _, r := range route {
+		providers = append(providers, r.Provider)
+	}
+	require.Equal(t, []string{"pyth-lazer", "stork-fast", "pyth", "redstone"}, providers)
+	require.Equal(t, types.FeedId("1"), route[0].FeedId)
+	require.Equal(t, OracleClassLazer, route[0].Class)
+	require.Equal(t, 0, *route[0].Priority)
+	require.Equal(t, "0.6", route[0].Weight.String())
+	require.Equal(t, "1.5", route[1].StalenessThreshold.String())
+	require.Equal(t, OracleClassNone, route[2].Class)
+	require.Nil(t, route[2].Priority)
+	require.Nil(t, route[2].Weight)
+
+	*route[0].Priority = 7
+	require.Equal(t, 0, *c.GetOracleRoute("BTC")[0].Priority)
+
+	require.Empty(t, c.GetOracleRoute("LTC"))
+	require.Nil(t, c.GetOracleRoute("DOGE"))
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+var (
+	ErrVPINotFound = errors.New("no vpi params")
+	// ErrVPIOutOfRetention is returned for lookups before the oldest retained
+	// entry of an asset whose older entries were dropped by the retention.
+	ErrVPIOutOfRetention = errors.New("vpi params are out of retention")
+)
+
+// VPIRetention bounds the VPI history kept per asset. The zero value keeps the
+// whole history. When both limits are set the stricter one wins.
+type VPIRetention struct {
+	// MaxAge drops entries superseded before now minus MaxAge. The entry in
+	// effect at that moment is kept so lookups inside the window still resolve.
+	MaxAge time.Duration
+	// MaxEntries keeps at most that many of the newest entries.
+	MaxEntries int
+}
+
+// start returns the index of the oldest entry to keep from timestamps sorted
+// in ascending order.
+func (r VPIRetention) start(timestamps []int64, now time.Time) int {
+	start := 0
+	if r.MaxAge > 0 {
+		cutoff := now.Add(-r.MaxAge).UnixMilli()
+		if i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] > cutoff }) - 1; i > start {
+			start = i
+		}
+	}
+	if r.MaxEntries > 0 && len(timestamps)-r.MaxEntries > start {
+		start = len(timestamps) - r.MaxEntries
+	}
+	return start
+}
+
+// LookupVPIParams is GetVPIParamsAtTimestamp telling a missing entry apart from
+// one dropped by the retention.
+func (c *configDiscovery) LookupVPIParams(name string, ts int64) (*types.VPIParamsParsed, error) {
+	entries := c.VPIHistorySorted[name]
+	if i := vpiIndexAt(entries, ts); i >= 0 {
+		params := entries[i].Clone()
+		return &params, nil
+	}
+	if from, ok := c.vpiRetainedFrom[name]; ok && ts < from {
+		return nil, errors.Wrapf(ErrVPIOutOfRetention, "%s at %d, retained from %d", name, ts, from)
+	}
+	return nil, errors.Wrapf(ErrVPINotFound, "%s at %d", name, ts)
+}
+
+type VPIAssetStats struct {
+	Entries int
+	Oldest  int64
+	Newest  int64
+	// RetainedFrom is the oldest retained timestamp when the retention dropped
+	// older entries, zero otherwise.
+	RetainedFrom int64
+	// Bytes estimates the size of the entry values: the timestamps and the
+	// magnitudes of the numbers, without the overhead of the containers.
+	Bytes int
+}
+
+type VPIHistoryStats struct {
+	Entries int
+	Bytes   int
+	Assets  map[string]VPIAssetStats
+}
+
+// GetVPIHistoryStats reports the size of the retained VPI history.
+func (c *configDiscovery) GetVPIHistoryStats() VPIHistoryStats {
+	stats := VPIHistoryStats{Assets: make(map[string]VPIAssetStats, len(c.VPIHistorySorted))}
+	for name, entries := range c.VPIHistorySorted {
+		s := VPIAssetStats{Entries: len(entries), RetainedFrom: c.vpiRetainedFrom[name]}
+		if len(entries) > 0 {
+			s.Oldest = entries[0].Timestamp
+			s.Newest = entries[len(entries)-1].Timestamp
+		}
+		for _, e := range entries {
+			s.Bytes += 8
+			for _, n := range []*big.Int{e.MarketDepthLong, e.MarketDepthShort, e.Spread, e.K} {
+				if n != nil {
+					s.Bytes += (n.BitLen() + 7) / 8
+				}
+			}
+		}
+		stats.Entries += s.Entries
+		stats.Bytes += s.Bytes
+		stats.Assets[name] = s
+	}
+	return stats
+}
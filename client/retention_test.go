@@ -0,0 +1,107 @@
+package client
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestVPIRetentionStart(t *testing.T) {
+	now := time.UnixMilli(10_000)
+	timestamps := []int64{1000, 2000, 5000, 8000, 9000}
+
+	for _, tc := range []struct {
+		name string
+		r    VPIRetention
+		want int
+	}{
+		{name: "keep all", r: VPIRetention{}, want: 0},
+		{name: "max age keeps the entry in effect at the cutoff", r: VPIRetention{MaxAge: 6 * time.Second}, want: 1},
+		{name: "max age on an entry", r: VPIRetention{MaxAge: 5 * time.Second}, want: 2},
+		{name: "max age before the first entry", r: VPIRetention{MaxAge: time.Minute}, want: 0},
+		{name: "max entries", r: VPIRetention{MaxEntries: 2}, want: 3},
+		{name: "max entries above the count", r: VPIRetention{MaxEntries: 10}, want: 0},
+		{name: "stricter limit wins", r: VPIRetention{MaxAge: 5 * time.Second, MaxEntries: 4}, want: 2},
+	} {
+		require.Equal(t, tc.want, tc.r.start(timestamps, now), tc.name)
+	}
+}
+
+func vpiHistoryAt(timestamps ...int64) map[string]map[string]types.VPIParams {
+	h := map[string]types.VPIParams{}
+	for _, ts := range timestamps {
+		h[strconv.FormatInt(ts, 10)] = types.VPIParams{MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"}
+	}
+	return map[string]map[string]types.VPIParams{"BTC": h}
+}
+
+func TestVPIRetentionOnRefresh(t *testing.T) {
+	clock := schedule.NewManualClock(time.UnixMilli(10_000))
+	s := newTestServer(t)
+	s.set("/vpi-history", vpiHistoryAt(1000, 2000, 5000, 8000))
+	c := newTestClient(t, s, WithClock(clock), WithVPIRetention(VPIRetention{MaxAge: 6 * time.Second}))
+
+	require.Equal(t, []int64{2000, 5000, 8000}, timestamps(c.VPIHistorySorted["BTC"]))
+
+	params, err := c.LookupVPIParams("BTC", 3000)
+	require.NoError(t, err)
+	require.Equal(t, int64(2000), params.Timestamp)
+	// The result is a copy the caller may change.
+	params.Spread.SetInt64(42)
+	require.Equal(t, int64(1), c.VPIHistory["BTC"][2000].Spread.Int64())
+
+	_, err = c.LookupVPIParams("BTC", 1500)
+	require.True(t, errors.Is(err, ErrVPIOutOfRetention))
+	_, ok := c.GetVPIParamsAtTimestamp("BTC", 1500)
+	require.False(t, ok)
+
+	_, err = c.LookupVPIParams("LTC", 3000)
+	require.True(t, errors.Is(err, ErrVPINotFound))
+
+	// Merged deltas are pruned as the window moves on.
+	clock.Advance(4 * time.Second)
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "8000")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", vpiHistoryAt(12_000))
+	require.Equal(t, []int64{8000, 12_000}, timestamps(c.VPIHistorySorted["BTC"]))
+	_, err = c.LookupVPIParams("BTC", 6000)
+	require.True(t, errors.Is(err, ErrVPIOutOfRetention))
+}
+
+func TestVPIRetentionMaxEntriesWithoutSinceSupport(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/vpi-history", vpiHistoryAt(1000, 2000, 3000))
+	c := newTestClient(t, s, WithVPIRetention(VPIRetention{MaxEntries: 2}))
+	require.Equal(t, []int64{2000, 3000}, timestamps(c.VPIHistorySorted["BTC"]))
+
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", vpiHistoryAt(1000, 2000, 3000, 4000))
+	require.Equal(t, []int64{3000, 4000}, timestamps(c.VPIHistorySorted["BTC"]))
+	_, err := c.LookupVPIParams("BTC", 2500)
+	require.True(t, errors.Is(err, ErrVPIOutOfRetention))
+}
+
+func TestGetVPIHistoryStats(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/vpi-history", vpiHistoryAt(1000, 2000, 3000))
+	c := newTestClient(t, s, WithVPIRetention(VPIRetention{MaxEntries: 2}))
+
+	stats := c.GetVPIHistoryStats()
+	require.Equal(t, 2, stats.Entries)
+	btc := stats.Assets["BTC"]
+	require.Equal(t, 2, btc.Entries)
+	require.Equal(t, int64(2000), btc.Oldest)
+	require.Equal(t, int64(3000), btc.Newest)
+	require.Equal(t, int64(2000), btc.RetainedFrom)
+	// A timestamp and four one byte numbers per entry.
+	require.Equal(t, 24, btc.Bytes)
+	require.Equal(t, btc.Bytes, stats.Bytes)
+
+	full := newTestClient(t, s).GetVPIHistoryStats()
+	require.Equal(t, 3, full.Entries)
+	require.Equal(t, int64(0), full.Assets["BTC"].RetainedFrom)
+	require.Equal(t, 36, full.Bytes)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// StaleConfigError is returned when the service responds with a config that is
+// not newer than the applied one, typically from a lagging replica.
+type StaleConfigError struct {
+	Current  time.Time
+	Received time.Time
+}
+
+func (e *StaleConfigError) Error() string {
+	return fmt.Sprintf("config composed at %s is not newer than the current one composed at %s",
+		e.Received.Format(time.RFC3339Nano), e.Current.Format(time.RFC3339Nano))
+}
+
+// checkComposedAt returns the parsed composedAt of cfg, or an error when applying
+// it would roll the client back. A composedAt that doesn't parse can't be
+// ordered against the current one, so it is refused like an older config;
+// when it is applied anyway, the current composedAt is kept so that later
+// configs are still checked against it.
+func (c *configDiscovery) checkComposedAt(cfg *types.AppConfig) (time.Time, error) {
+	known := c.Snapshot != nil && !c.Snapshot.ComposedAt.IsZero()
+	allowed := c.allowOlderConfigs || c.allowNextRollback.Load()
+
+	composedAt, err := cfg.ComposedAtTime()
+	if err != nil {
+		switch {
+		case !known:
+			log.Warn().Err(err).Msg("can't order configs, applying without rollback protection")
+			return time.Time{}, nil
+		case allowed:
+			log.Warn().Err(err).Time("current", c.Snapshot.ComposedAt).Msg("can't order configs, applying and keeping the current composedAt")
+			return c.Snapshot.ComposedAt, nil
+		}
+		c.metrics.ConfigRejected(RejectReasonRollback)
+		return time.Time{}, errors.Wrap(err, "can't order config against the current one")
+	}
+
+	if !known || composedAt.After(c.Snapshot.ComposedAt) {
+		return composedAt, nil
+	}
+
+	if allowed {
+		log.Warn().Time("current", c.Snapshot.ComposedAt).Time("received", composedAt).Msg("applying config older than the current one")
+		return composedAt, nil
+	}
+
+	c.metrics.ConfigRejected(RejectReasonRollback)
+	return time.Time{}, &StaleConfigError{Current: c.Snapshot.ComposedAt, Received: composedAt}
+}
+
+// AllowNextRollback lets the next refresh apply a config older than the current
+// one, e.g. after an intentional rollback on the server.
+func (c *configDiscovery) AllowNextRollback() {
+	c.allowNextRollback.Store(true)
+}
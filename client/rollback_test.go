@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/test-go/testify/require"
+)
+
+func TestOlderConfigIsRefused(t *testing.T) {
+	s := newTestServer(t)
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	metrics := &countingMetrics{}
+	c := newTestClient(t, s, WithMetrics(metrics))
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), c.GetSnapshot().ComposedAt)
+
+	s.set("", testAppConfig("2024-01-01T00:00:00Z"))
+	err := c.FetchConfig()
+	var staleErr *StaleConfigError
+	require.True(t, errors.As(err, &staleErr))
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), staleErr.Received)
+	require.Equal(t, 1, metrics.rejected[RejectReasonRollback])
+	require.Equal(t, "2024-01-02T00:00:00Z", c.GetConfig().ComposedAt)
+	require.Len(t, s.requested("/vpi-history"), 1)
+
+	s.set("", testAppConfig("2024-01-03T00:00:00Z"))
+	require.NoError(t, c.FetchConfig())
+	require.Equal(t, "2024-01-03T00:00:00Z", c.GetConfig().ComposedAt)
+}
+
+func TestAllowNextRollback(t *testing.T) {
+	s := newTestServer(t)
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	c := newTestClient(t, s)
+
+	s.set("", testAppConfig("2024-01-01T00:00:00Z"))
+	c.AllowNextRollback()
+	require.NoError(t, c.FetchConfig())
+	require.Equal(t, "2024-01-01T00:00:00Z", c.GetConfig().ComposedAt)
+
+	s.set("", testAppConfig("2023-12-31T00:00:00Z"))
+	require.Error(t, c.FetchConfig())
+}
+
+func TestAllowOlderConfigs(t *testing.T) {
+	s := newTestServer(t)
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	c := newTestClient(t, s, WithAllowOlderConfigs())
+
+	s.set("", testAppConfig("2024-01-01T00:00:00Z"))
+	require.NoError(t, c.FetchConfig())
+	require.Equal(t, "2024-01-01T00:00:00Z", c.GetConfig().ComposedAt)
+}
+
+func TestUnparseableComposedAtKeepsRollbackProtection(t *testing.T) {
+	s := newTestServer(t)
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	metrics := &countingMetrics{}
+	c := newTestClient(t, s, WithMetrics(metrics))
+
+	s.set("", testAppConfig("yesterday"))
+	require.EqualError(t, c.FetchConfig(), `can't order config against the current one: unsupported composedAt "yesterday"`)
+	require.Equal(t, 1, metrics.rejected[RejectReasonRollback])
+	require.Equal(t, "2024-01-02T00:00:00Z", c.GetConfig().ComposedAt)
+
+	// Applied on request, it keeps the current composedAt for later checks.
+	c.AllowNextRollback()
+	require.NoError(t, c.FetchConfig())
+	require.Equal(t, "yesterday", c.GetConfig().ComposedAt)
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), c.GetSnapshot().ComposedAt)
+
+	s.set("", testAppConfig("2024-01-01T00:00:00Z"))
+	var staleErr *StaleConfigError
+	require.True(t, errors.As(c.FetchConfig(), &staleErr))
+
+	// Without a known composedAt there is nothing to protect.
+	s.set("", testAppConfig("yesterday"))
+	fresh := newTestClient(t, s)
+	require.True(t, fresh.GetSnapshot().ComposedAt.IsZero())
+}
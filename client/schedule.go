@@ -0,0 +1,122 @@
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// parseSchedules parses every resolved asset schedule. Malformed schedules are kept as
+// errors so that queries for that asset fail instead of guessing.
+func parseSchedules(schedules map[string]*types.AssetSchedule) (map[string]*schedule.Schedule, map[string]error) {
+	parsed := make(map[string]*schedule.Schedule)
+	failed := make(map[string]error)
+	for name, s := range schedules {
+		p, err := schedule.FromAssetSchedule(s)
+		if err != nil {
+			log.Warn().Err(err).Str("asset", name).Msg("malformed asset schedule")
+			failed[name] = err
+			continue
+		}
+		parsed[name] = p
+	}
+	return parsed, failed
+}
+
+// effectiveSchedules drops informational schedules, which never produce events.
+func effectiveSchedules(schedules map[string]*types.AssetSchedule, parsed map[string]*schedule.Schedule) map[string]*schedule.Schedule {
+	out := make(map[string]*schedule.Schedule)
+	for name, s := range parsed {
+		if schedules[name].IsEffective() {
+			out[name] = s
+		}
+	}
+	return out
+}
+
+func (c *configDiscovery) getParsedSchedule(name string) (*schedule.Schedule, error) {
+	if err, ok := c.ScheduleErrors[name]; ok {
+		return nil, errors.Wrapf(err, "schedule of %s", name)
+	}
+	s, ok := c.ParsedSchedules[name]
+	if !ok {
+		return nil, errors.Wrapf(schedule.ErrNoSchedule, "schedule of %s", name)
+	}
+	return s, nil
+}
+
+func (c *configDiscovery) IsMarketOpen(name string, t time.Time) (bool, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return false, err
+	}
+	return s.IsOpen(t), nil
+}
+
+// CurrentSession returns the session of the asset containing t, or nil when the
+// market is closed at t.
+func (c *configDiscovery) CurrentSession(name string, t time.Time) (*schedule.Session, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return nil, err
+	}
+	session, ok := s.CurrentSession(t)
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (c *configDiscovery) NextOpen(name string, t time.Time) (time.Time, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.NextOpen(t)
+}
+
+func (c *configDiscovery) NextClose(name string, t time.Time) (time.Time, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.NextClose(t)
+}
+
+func (c *configDiscovery) TimeUntilClose(name string, t time.Time) (time.Duration, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.TimeUntilClose(t)
+}
+
+func (c *configDiscovery) GetCalendar(name string, from, to time.Time) ([]schedule.CalendarSession, error) {
+	s, err := c.getParsedSchedule(name)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Calendar(name, s, c.GetScheduleType(name), from, to), nil
+}
+
+// GetResolvedSchedule returns the schedule of an asset merged from
+// /assets-schedule and its asset config, see schedule.Resolve for precedence.
+func (c *configDiscovery) GetResolvedSchedule(name string) *types.AssetSchedule {
+	return c.ResolvedSchedules[name]
+}
+
+// GetResolvedSchedules returns the resolved schedule of every asset that has
+// one in either source.
+func (c *configDiscovery) GetResolvedSchedules() map[string]*types.AssetSchedule {
+	return c.ResolvedSchedules
+}
+
+func (c *configDiscovery) GetScheduleMismatches() []schedule.Mismatch {
+	if c.Snapshot == nil {
+		return nil
+	}
+	return schedule.Mismatches(c.Snapshot.Schedules, c.Snapshot.AssetConfigs)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+	"github.com/test-go/testify/require"
+)
+
+func TestMarketHours(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"LTC":  {ScheduleTimeZone: "America/New_York", Schedule: "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-", Holidays: `["2024-01-15"]`},
+		"SPCX": {Schedule: "9:30-16:00"},
+	}})
+	c := newTestClient(t, s)
+
+	open, err := c.IsMarketOpen("LTC", time.Date(2024, 1, 8, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, open)
+
+	session, err := c.CurrentSession("LTC", time.Date(2024, 1, 8, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 8, 21, 0, 0, 0, time.UTC), session.Close.UTC())
+
+	session, err = c.CurrentSession("LTC", time.Date(2024, 1, 6, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Nil(t, session)
+
+	next, err := c.NextOpen("LTC", time.Date(2024, 1, 12, 22, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 16, 14, 30, 0, 0, time.UTC), next.UTC())
+
+	next, err = c.NextClose("LTC", time.Date(2024, 1, 12, 22, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 16, 21, 0, 0, 0, time.UTC), next.UTC())
+
+	left, err := c.TimeUntilClose("LTC", time.Date(2024, 1, 8, 20, 15, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 45*time.Minute, left)
+
+	_, err = c.IsMarketOpen("SPCX", time.Now())
+	require.True(t, errors.Is(err, schedule.ErrMalformed))
+
+	_, err = c.IsMarketOpen("BTC", time.Now())
+	require.True(t, errors.Is(err, schedule.ErrNoSchedule))
+}
+
+func TestScheduleEvents(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	usEquities := "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-"
+
+	s := newTestServer(t)
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"LTC": {ScheduleTimeZone: "America/New_York", Schedule: usEquities},
+		"BTC": {ScheduleTimeZone: "America/New_York", Schedule: usEquities, ScheduleType: types.ScheduleTypeInfo},
+	}})
+
+	clock := schedule.NewManualClock(time.Date(2024, 1, 12, 8, 0, 0, 0, ny))
+	var events []schedule.Event
+	c := newTestClient(t, s, WithClock(clock), WithScheduleEventHandler(func(e schedule.Event) {
+		events = append(events, e)
+	}))
+
+	clock.Advance(2 * time.Hour)
+	require.Equal(t, []schedule.Event{{Asset: "LTC", Type: schedule.EventOpen, At: time.Date(2024, 1, 12, 9, 30, 0, 0, ny)}}, events)
+
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"LTC": {ScheduleTimeZone: "America/New_York", Schedule: "09:30-15:00|09:30-15:00|09:30-15:00|09:30-15:00|09:30-15:00|-|-"},
+	}})
+	require.NoError(t, c.FetchConfig())
+
+	clock.Advance(6 * time.Hour)
+	require.Equal(t, schedule.Event{Asset: "LTC", Type: schedule.EventClose, At: time.Date(2024, 1, 12, 15, 0, 0, 0, ny)}, events[1])
+	require.Len(t, events, 2)
+}
+
+func TestGetCalendar(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"SPCX": {ScheduleTimeZone: "America/New_York", Schedule: "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-", ScheduleType: types.ScheduleTypeInfo},
+	}})
+	c := newTestClient(t, s, WithoutDefaultValidators())
+
+	sessions, err := c.GetCalendar("SPCX", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, sessions, 5)
+	require.True(t, sessions[0].Informational)
+
+	_, err = c.GetCalendar("BTC", time.Now(), time.Now().Add(time.Hour))
+	require.True(t, errors.Is(err, schedule.ErrNoSchedule))
+}
+
+func TestResolvedSchedules(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"LTC": {Schedule: "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-"},
+	}})
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", Schedule: "00:00-12:00|-|-|-|-|-|-"},
+		{Index: 11, Name: "LTC", ScheduleTimeZone: "America/New_York", Schedule: "10:00-16:00|-|-|-|-|-|-"},
+	})
+	c := newTestClient(t, s)
+
+	require.Equal(t, "America/New_York", c.GetResolvedSchedule("LTC").ScheduleTimeZone)
+	open, err := c.IsMarketOpen("LTC", time.Date(2024, 1, 9, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, open)
+
+	open, err = c.IsMarketOpen("BTC", time.Date(2024, 1, 8, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, open)
+
+	require.Len(t, c.GetScheduleMismatches(), 1)
+	require.Equal(t, "schedule", c.GetScheduleMismatches()[0].Field)
+	require.Len(t, c.GetValidationWarnings(), 1)
+}
+
+func TestUnknownTimeZoneIsReported(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+		"LTC": {ScheduleTimeZone: "America/Gotham", Schedule: "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-"},
+	}})
+	c := newTestClient(t, s)
+
+	warnings := c.GetValidationWarnings()
+	require.Len(t, warnings, 1)
+	require.Equal(t, "LTC", warnings[0].Asset)
+	require.Equal(t, validation.RuleTimeZones, warnings[0].Rule)
+
+	_, err := c.IsMarketOpen("LTC", time.Now())
+	require.Error(t, err)
+}
@@ -9,18 +9,18 @@ import (
 
 func TestConfigDiscoveryScheduleHelpers(t *testing.T) {
 	c := &configDiscovery{
-		Schedules: map[string]*types.AssetSchedule{},
+		ResolvedSchedules: map[string]*types.AssetSchedule{},
 	}
 	require.Equal(t, types.ScheduleTypeEffective, c.GetScheduleType("BTC"))
 	require.True(t, c.IsScheduleEffective("BTC"))
 
-	c.Schedules["SPCX"] = &types.AssetSchedule{
+	c.ResolvedSchedules["SPCX"] = &types.AssetSchedule{
 		ScheduleType: types.ScheduleTypeInfo,
 	}
 	require.Equal(t, types.ScheduleTypeInfo, c.GetScheduleType("SPCX"))
 	require.False(t, c.IsScheduleEffective("SPCX"))
 
-	c.Schedules["ETH"] = &types.AssetSchedule{}
+	c.ResolvedSchedules["ETH"] = &types.AssetSchedule{}
 	require.Equal(t, types.ScheduleTypeEffective, c.GetScheduleType("ETH"))
 	require.True(t, c.IsScheduleEffective("ETH"))
 }
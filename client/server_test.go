@@ -0,0 +1,121 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+type testServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	resources map[string][]byte
+	headers   map[string]http.Header
+	requests  []string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{resources: map[string][]byte{}, headers: map[string]http.Header{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		body, ok := s.resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range s.headers[r.URL.Path] {
+			w.Header()[k] = v
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+
+	s.set("", testAppConfig("2024-01-01T00:00:00Z"))
+	s.set("/assets", []*types.Asset{
+		{Name: "BTC", Index: 0, Type: "crypto"},
+		{Name: "LTC", Index: 11, Type: "crypto"},
+	})
+	s.set("/assets-schedule", types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{}})
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", Oracles: []types.OracleConfig{{Provider: "pyth-lazer"}}},
+		{Index: 11, Name: "LTC", Oracles: []types.OracleConfig{{Provider: "pyth"}}},
+	})
+	s.set("/vpi-history", map[string]map[string]types.VPIParams{
+		"BTC": {"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"}},
+	})
+	return s
+}
+
+func testAppConfig(composedAt string) types.AppConfig {
+	usdt := types.CollateralAsset{Name: "USDT", Decimals: 6, AssetId: "usdt-id"}
+	return types.AppConfig{
+		ComposedAt:       composedAt,
+		CollateralAssets: []types.CollateralAsset{usdt},
+		OpenedMarkets: []types.Market{
+			{Name: "BTC/USDT", Address: "market-btc", VaultAddress: "vault-usdt", BaseAsset: "BTC", QuoteAsset: "USDT"},
+			{Name: "LTC/USDT", Address: "market-ltc", VaultAddress: "vault-usdt", BaseAsset: "LTC", QuoteAsset: "USDT"},
+		},
+		Vaults: []types.Vault{
+			{Asset: usdt, VaultAddress: "vault-usdt", LpJettonMaster: "lp-usdt"},
+		},
+	}
+}
+
+func (s *testServer) uri() string {
+	return s.URL + "/config"
+}
+
+func (s *testServer) set(path string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	s.setRaw(path, body)
+}
+
+func (s *testServer) setRaw(path string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources["/config"+path] = body
+}
+
+func (s *testServer) setHeader(path, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.headers["/config"+path] == nil {
+		s.headers["/config"+path] = http.Header{}
+	}
+	s.headers["/config"+path].Set(key, value)
+}
+
+func (s *testServer) body(path string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resources["/config"+path]
+}
+
+func (s *testServer) requested(prefix string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, r := range s.requests {
+		if strings.HasPrefix(r, "/config"+prefix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func newTestClient(t *testing.T, s *testServer, opt ...Opt) *configDiscovery {
+	c, err := New(s.uri(), opt...)
+	require.NoError(t, err)
+	return c.(*configDiscovery)
+}
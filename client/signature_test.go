@@ -0,0 +1,107 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/signature"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+type countingMetrics struct {
+	rejected map[string]int
+}
+
+func (m *countingMetrics) ConfigRejected(reason string) {
+	if m.rejected == nil {
+		m.rejected = map[string]int{}
+	}
+	m.rejected[reason]++
+}
+
+var testResources = []string{"", "/assets", "/assets-schedule", "/assets-config", "/vpi-history"}
+
+func signAll(s *testServer, priv ed25519.PrivateKey) {
+	for _, path := range testResources {
+		s.setHeader(path, signature.Header, base64.StdEncoding.EncodeToString(ed25519.Sign(priv, s.body(path))))
+	}
+}
+
+func TestSignedConfigIsApplied(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	s := newTestServer(t)
+	signAll(s, priv)
+
+	c := newTestClient(t, s, WithSignatureVerifier(signature.NewVerifier(pub)))
+	require.NotNil(t, c.GetMarketByAddress("market-btc"))
+}
+
+func TestSignatureVerifierMixesWithCallerOptions(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	s := newTestServer(t)
+	signAll(s, priv)
+
+	called := 0
+	var own Opt = func(config *types.AppConfig) {
+		require.NotNil(t, config)
+		called++
+	}
+	c := newTestClient(t, s, own, WithSignatureVerifier(signature.NewVerifier(pub)))
+	require.Equal(t, 1, called)
+	require.NotNil(t, c.verifier)
+
+	// Outside of New the options of this package do nothing.
+	WithSignatureVerifier(nil)(c.Config)
+	require.NotNil(t, c.verifier)
+}
+
+func TestCompanionSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	s := newTestServer(t)
+	for _, path := range testResources {
+		s.setRaw(path+".sig", []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, s.body(path)))))
+	}
+
+	c := newTestClient(t, s, WithSignatureVerifier(signature.NewVerifier(pub)))
+	require.NotNil(t, c.GetAssetByName("LTC"))
+}
+
+func TestBadlySignedConfigKeepsLastGoodSnapshot(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, attackerPriv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	s := newTestServer(t)
+	signAll(s, priv)
+	metrics := &countingMetrics{}
+	c := newTestClient(t, s, WithSignatureVerifier(signature.NewVerifier(pub)), WithMetrics(metrics))
+
+	tampered := testAppConfig("2024-01-02T00:00:00Z")
+	tampered.OpenedMarkets[0].VaultAddress = "attacker-vault"
+	s.set("", tampered)
+	signAll(s, attackerPriv)
+
+	err = c.FetchConfig()
+	var sigErr *signature.Error
+	require.True(t, errors.As(err, &sigErr))
+	require.True(t, errors.Is(err, signature.ErrBadSignature))
+	require.Equal(t, 1, metrics.rejected[RejectReasonSignature])
+	require.Equal(t, "2024-01-01T00:00:00Z", c.GetConfig().ComposedAt)
+	require.Equal(t, "vault-usdt", c.GetMarketByAddress("market-btc").VaultAddress)
+}
+
+func TestUnsignedConfigIsRefused(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	s := newTestServer(t)
+
+	_, err = New(s.uri(), WithSignatureVerifier(signature.NewVerifier(pub)))
+	require.True(t, errors.Is(err, signature.ErrUnsigned))
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func receiveUpdate(t *testing.T, c *configDiscovery) *types.AppConfig {
+	select {
+	case cfg := <-c.UpdatesChannel():
+		return cfg
+	case <-time.After(time.Second):
+		t.Fatal("no update received")
+		return nil
+	}
+}
+
+func TestUpdatesCarryTheAppliedConfig(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+	require.Equal(t, "2024-01-01T00:00:00Z", receiveUpdate(t, c).ComposedAt)
+
+	// Updates that are not received yet keep the config of their own refresh.
+	for _, composedAt := range []string{"2024-01-02T00:00:00Z", "2024-01-03T00:00:00Z"} {
+		s.set("", testAppConfig(composedAt))
+		require.NoError(t, c.FetchConfig())
+	}
+	received := map[string]bool{
+		receiveUpdate(t, c).ComposedAt: true,
+		receiveUpdate(t, c).ComposedAt: true,
+	}
+	require.Equal(t, map[string]bool{"2024-01-02T00:00:00Z": true, "2024-01-03T00:00:00Z": true}, received)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+	"github.com/test-go/testify/require"
+)
+
+func TestInvalidConfigKeepsPreviousSnapshot(t *testing.T) {
+	s := newTestServer(t)
+	metrics := &countingMetrics{}
+	c := newTestClient(t, s, WithMetrics(metrics))
+
+	broken := testAppConfig("2024-01-02T00:00:00Z")
+	broken.OpenedMarkets[0].VaultAddress = "missing-vault"
+	s.set("", broken)
+	s.set("/assets", []*types.Asset{{Name: "BTC", Index: 0}, {Name: "LTC", Index: 0}})
+
+	err := c.FetchConfig()
+	var vErr *validation.Error
+	require.True(t, errors.As(err, &vErr))
+	require.Len(t, vErr.Violations, 2)
+	require.Equal(t, validation.RuleReferentialIntegrity, vErr.Violations[0].Rule)
+	require.Equal(t, validation.RuleDuplicateKeys, vErr.Violations[1].Rule)
+	require.Equal(t, "asset index 0", vErr.Violations[1].Subject)
+	require.Equal(t, 1, metrics.rejected[RejectReasonValidation])
+	require.Equal(t, "2024-01-01T00:00:00Z", c.GetConfig().ComposedAt)
+	require.Equal(t, "vault-usdt", c.GetMarketByAddress("market-btc").VaultAddress)
+	require.Equal(t, 11, c.GetAssetByName("LTC").Index)
+}
+
+func TestCustomValidators(t *testing.T) {
+	s := newTestServer(t)
+	noLTC := validation.Func("no-ltc", func(snapshot *types.Snapshot) []validation.Violation {
+		for _, a := range snapshot.Assets {
+			if a.Name == "LTC" {
+				return []validation.Violation{{Severity: validation.SeverityWarning, Subject: "asset LTC", Message: "LTC is listed"}}
+			}
+		}
+		return nil
+	})
+
+	c := newTestClient(t, s, WithValidators(noLTC))
+	require.Len(t, c.GetValidationWarnings(), 1)
+	require.Equal(t, "no-ltc", c.GetValidationWarnings()[0].Rule)
+
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	s.set("/assets", []*types.Asset{{Name: "BTC", Index: 0}, {Name: "LTC", Index: 0}})
+	_, err := New(s.uri(), WithoutDefaultValidators())
+	require.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+)
+
+// parseCurrentVPI parses the VPI carried by each asset config. Asset configs
+// don't say since when their VPI applies, so it is stamped with the timestamp of
+// the newest history entry of the asset when that entry has the same params, and
+// left at zero otherwise. Asset configs without VPI are left out, and so are
+// those whose VPI doesn't parse, with a warning; rejecting malformed numbers is
+// left to the ParseableNumbers validator.
+func parseCurrentVPI(configs []*types.AssetConfig, history map[string][]types.VPIParamsParsed) (map[string]types.VPIParamsParsed, []validation.Violation) {
+	current := make(map[string]types.VPIParamsParsed, len(configs))
+	var warnings []validation.Violation
+	for _, a := range configs {
+		if a.VPI.IsEmpty() {
+			continue
+		}
+		p, err := a.VPI.Parse()
+		if err != nil {
+			warnings = append(warnings, validation.Violation{
+				Rule:     validation.RuleParseableNumbers,
+				Severity: validation.SeverityWarning,
+				Subject:  "asset config " + a.Name,
+				Asset:    a.Name,
+				Message:  "vpi skipped: " + err.Error(),
+			})
+			continue
+		}
+		if entries := history[a.Name]; len(entries) > 0 && sameVPI(p, entries[len(entries)-1]) {
+			p.Timestamp = entries[len(entries)-1].Timestamp
+		}
+		current[a.Name] = p
+	}
+	return current, warnings
+}
+
+// sameVPI reports whether a and b have the same params, ignoring timestamps.
+func sameVPI(a, b types.VPIParamsParsed) bool {
+	equal := func(x, y *big.Int) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Cmp(y) == 0
+	}
+	return equal(a.MarketDepthLong, b.MarketDepthLong) && equal(a.MarketDepthShort, b.MarketDepthShort) &&
+		equal(a.Spread, b.Spread) && equal(a.K, b.K)
+}
+
+// sortVPIHistory builds per asset slices ordered by timestamp for binary search.
+func sortVPIHistory(history map[string]map[int64]types.VPIParamsParsed) map[string][]types.VPIParamsParsed {
+	sorted := make(map[string][]types.VPIParamsParsed, len(history))
+	for name, h := range history {
+		entries := make([]types.VPIParamsParsed, 0, len(h))
+		for _, params := range h {
+			entries = append(entries, params)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Timestamp < entries[j].Timestamp })
+		sorted[name] = entries
+	}
+	return sorted
+}
+
+// vpiIndexAt returns the index of the last entry with Timestamp <= ts, or -1.
+func vpiIndexAt(entries []types.VPIParamsParsed, ts int64) int {
+	return sort.Search(len(entries), func(i int) bool { return entries[i].Timestamp > ts }) - 1
+}
+
+func cloneVPIEntries(entries []types.VPIParamsParsed) []types.VPIParamsParsed {
+	out := make([]types.VPIParamsParsed, len(entries))
+	for i, e := range entries {
+		out[i] = e.Clone()
+	}
+	return out
+}
+
+// GetVPIParamsInRange returns the entries in effect during [from, to], starting
+// with the one in effect at from.
+func (c *configDiscovery) GetVPIParamsInRange(name string, from, to int64) ([]types.VPIParamsParsed, bool) {
+	entries, ok := c.VPIHistorySorted[name]
+	if !ok {
+		return nil, false
+	}
+	if to < from {
+		return []types.VPIParamsParsed{}, true
+	}
+	start := vpiIndexAt(entries, from)
+	if start < 0 {
+		start = 0
+	}
+	end := vpiIndexAt(entries, to) + 1
+	if end < start {
+		end = start
+	}
+	return cloneVPIEntries(entries[start:end]), true
+}
+
+// GetVPIChangesSince returns the entries that took effect strictly after ts.
+func (c *configDiscovery) GetVPIChangesSince(name string, ts int64) ([]types.VPIParamsParsed, bool) {
+	entries, ok := c.VPIHistorySorted[name]
+	if !ok {
+		return nil, false
+	}
+	return cloneVPIEntries(entries[vpiIndexAt(entries, ts)+1:]), true
+}
+
+func (c *configDiscovery) GetLatestVPI(name string) (*types.VPIParamsParsed, bool) {
+	entries := c.VPIHistorySorted[name]
+	if len(entries) == 0 {
+		return nil, false
+	}
+	latest := entries[len(entries)-1].Clone()
+	return &latest, true
+}
+
+// GetCurrentVPI returns the VPI an asset trades with now: the one from its asset
+// config, or the newest history entry when the config carries none. The two
+// are checked against each other by validation.VPIConsistency. The Timestamp
+// of a VPI from the asset config is that of the newest history entry with the
+// same params, or zero when the history doesn't have it.
+func (c *configDiscovery) GetCurrentVPI(name string) (*types.VPIParamsParsed, bool) {
+	if p, ok := c.CurrentVPI[name]; ok {
+		current := p.Clone()
+		return &current, true
+	}
+	return c.GetLatestVPI(name)
+}
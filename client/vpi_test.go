@@ -0,0 +1,282 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+	"github.com/test-go/testify/require"
+)
+
+func vpiTestClient(history map[string]map[int64]types.VPIParamsParsed) *configDiscovery {
+	return &configDiscovery{VPIHistory: history, VPIHistorySorted: sortVPIHistory(history)}
+}
+
+func vpiEntry(ts int64) types.VPIParamsParsed {
+	return types.VPIParamsParsed{
+		Timestamp:        ts,
+		MarketDepthLong:  big.NewInt(ts * 10),
+		MarketDepthShort: big.NewInt(ts * 20),
+		Spread:           big.NewInt(1),
+		K:                big.NewInt(2),
+	}
+}
+
+func TestGetVPIParamsAtTimestamp(t *testing.T) {
+	c := vpiTestClient(map[string]map[int64]types.VPIParamsParsed{
+		"BTC": {100: vpiEntry(100), 300: vpiEntry(300), 200: vpiEntry(200)},
+		"ETH": {},
+	})
+
+	for _, tc := range []struct {
+		ts   int64
+		want int64
+		ok   bool
+	}{
+		{ts: 99, ok: false},
+		{ts: 100, want: 100, ok: true},
+		{ts: 199, want: 100, ok: true},
+		{ts: 200, want: 200, ok: true},
+		{ts: 1000, want: 300, ok: true},
+	} {
+		params, ok := c.GetVPIParamsAtTimestamp("BTC", tc.ts)
+		require.Equal(t, tc.ok, ok, "ts %d", tc.ts)
+		if ok {
+			require.Equal(t, tc.want, params.Timestamp)
+		}
+	}
+
+	_, ok := c.GetVPIParamsAtTimestamp("ETH", 1000)
+	require.False(t, ok)
+	_, ok = c.GetVPIParamsAtTimestamp("DOGE", 1000)
+	require.False(t, ok)
+}
+
+// vpiAtTimestampScan is the previous implementation, kept as a baseline for
+// the benchmarks.
+func vpiAtTimestampScan(history map[int64]types.VPIParamsParsed, ts int64) (*types.VPIParamsParsed, bool) {
+	var bestTS int64 = -1
+	var bestParams types.VPIParamsParsed
+	for timestamp, params := range history {
+		if timestamp <= ts && timestamp > bestTS {
+			bestTS = timestamp
+			bestParams = params
+		}
+	}
+	if bestTS == -1 {
+		return nil, false
+	}
+	return &bestParams, true
+}
+
+func benchmarkHistory(n int) map[string]map[int64]types.VPIParamsParsed {
+	h := make(map[int64]types.VPIParamsParsed, n)
+	for i := 0; i < n; i++ {
+		ts := int64(1_700_000_000_000 + i*60_000)
+		h[ts] = vpiEntry(ts)
+	}
+	return map[string]map[int64]types.VPIParamsParsed{"BTC": h}
+}
+
+func BenchmarkVPIParamsAtTimestamp(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		history := benchmarkHistory(n)
+		c := vpiTestClient(history)
+		mid := int64(1_700_000_000_000 + n/2*60_000 + 30_000)
+
+		b.Run(fmt.Sprintf("map-scan/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, ok := vpiAtTimestampScan(history["BTC"], mid); !ok {
+					b.Fatal("not found")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("binary-search/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, ok := c.GetVPIParamsAtTimestamp("BTC", mid); !ok {
+					b.Fatal("not found")
+				}
+			}
+		})
+	}
+}
+
+func timestamps(entries []types.VPIParamsParsed) []int64 {
+	out := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Timestamp)
+	}
+	return out
+}
+
+func TestGetVPIParamsInRange(t *testing.T) {
+	c := vpiTestClient(map[string]map[int64]types.VPIParamsParsed{
+		"BTC": {100: vpiEntry(100), 200: vpiEntry(200), 300: vpiEntry(300), 400: vpiEntry(400)},
+	})
+
+	for _, tc := range []struct {
+		from, to int64
+		want     []int64
+	}{
+		{from: 150, to: 350, want: []int64{100, 200, 300}},
+		{from: 200, to: 300, want: []int64{200, 300}},
+		{from: 0, to: 150, want: []int64{100}},
+		{from: 0, to: 50, want: []int64{}},
+		{from: 500, to: 600, want: []int64{400}},
+		{from: 300, to: 200, want: []int64{}},
+	} {
+		entries, ok := c.GetVPIParamsInRange("BTC", tc.from, tc.to)
+		require.True(t, ok)
+		require.Equal(t, tc.want, timestamps(entries), "[%d, %d]", tc.from, tc.to)
+	}
+
+	_, ok := c.GetVPIParamsInRange("DOGE", 0, 1000)
+	require.False(t, ok)
+}
+
+func TestGetVPIChangesSinceAndLatest(t *testing.T) {
+	c := vpiTestClient(map[string]map[int64]types.VPIParamsParsed{
+		"BTC": {100: vpiEntry(100), 200: vpiEntry(200), 300: vpiEntry(300)},
+		"ETH": {},
+	})
+
+	changes, ok := c.GetVPIChangesSince("BTC", 100)
+	require.True(t, ok)
+	require.Equal(t, []int64{200, 300}, timestamps(changes))
+
+	changes, ok = c.GetVPIChangesSince("BTC", 300)
+	require.True(t, ok)
+	require.Empty(t, changes)
+
+	latest, ok := c.GetLatestVPI("BTC")
+	require.True(t, ok)
+	require.Equal(t, int64(300), latest.Timestamp)
+
+	_, ok = c.GetLatestVPI("ETH")
+	require.False(t, ok)
+}
+
+func TestVPIQueriesReturnCopies(t *testing.T) {
+	c := vpiTestClient(map[string]map[int64]types.VPIParamsParsed{
+		"BTC": {100: vpiEntry(100), 200: vpiEntry(200)},
+	})
+
+	entries, _ := c.GetVPIParamsInRange("BTC", 0, 1000)
+	entries[0].Timestamp = 999
+	entries[0].Spread.SetInt64(42)
+	_ = append(entries[:1], vpiEntry(150))
+
+	changes, _ := c.GetVPIChangesSince("BTC", 0)
+	changes[1].K.SetInt64(42)
+
+	latest, _ := c.GetLatestVPI("BTC")
+	latest.MarketDepthLong.SetInt64(42)
+
+	at, _ := c.GetVPIParamsAtTimestamp("BTC", 150)
+	at.K.SetInt64(42)
+
+	all, _ := c.GetVPIParamsInRange("BTC", 0, 1000)
+	require.Equal(t, []int64{100, 200}, timestamps(all))
+	require.Equal(t, int64(1), all[0].Spread.Int64())
+	require.Equal(t, int64(2), all[0].K.Int64())
+	require.Equal(t, int64(2), all[1].K.Int64())
+	require.Equal(t, int64(2000), all[1].MarketDepthLong.Int64())
+}
+
+func TestCurrentVPIFromAssetConfig(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", VPI: types.VPIParams{MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "3"}},
+		{Index: 11, Name: "LTC"},
+	})
+	s.set("/vpi-history", map[string]map[string]types.VPIParams{
+		"BTC": {"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"}},
+		"LTC": {
+			"1000": {MarketDepthLong: "10", MarketDepthShort: "20", Spread: "1", K: "2"},
+			"2000": {Spread: "1", K: "2"},
+		},
+	})
+	c := newTestClient(t, s)
+
+	btc, ok := c.GetCurrentVPI("BTC")
+	require.True(t, ok)
+	require.Equal(t, "3", btc.K.String())
+	// The history doesn't say since when K is 3.
+	require.Zero(t, btc.Timestamp)
+
+	// LTC has no VPI in its asset config and falls back to the newest history entry.
+	ltc, ok := c.GetCurrentVPI("LTC")
+	require.True(t, ok)
+	require.Equal(t, int64(1000), ltc.Timestamp)
+
+	_, ok = c.GetCurrentVPI("DOGE")
+	require.False(t, ok)
+
+	btc.K.SetInt64(42)
+	again, _ := c.GetCurrentVPI("BTC")
+	require.Equal(t, "3", again.K.String())
+
+	subjects := map[string]string{}
+	for _, w := range c.GetValidationWarnings() {
+		subjects[w.Subject] = w.Rule
+	}
+	require.Equal(t, map[string]string{
+		"asset config BTC":     validation.RuleVPIConsistency,
+		"vpi history LTC@2000": validation.RuleParseableNumbers,
+	}, subjects)
+
+	// Once the history has the params, their entry dates them.
+	s.set("", testAppConfig("2024-01-02T00:00:00Z"))
+	s.set("/vpi-history", map[string]map[string]types.VPIParams{
+		"BTC": {
+			"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"},
+			"3000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "3"},
+		},
+	})
+	require.NoError(t, c.FetchConfig())
+	btc, ok = c.GetCurrentVPI("BTC")
+	require.True(t, ok)
+	require.Equal(t, int64(3000), btc.Timestamp)
+}
+
+func TestCurrentVPISkipsUnparseableAssetConfig(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", VPI: types.VPIParams{MarketDepthLong: "100", Spread: "1", K: "3"}},
+		{Index: 11, Name: "LTC", VPI: types.VPIParams{MarketDepthLong: "10", MarketDepthShort: "20", Spread: "1", K: "2"}},
+	})
+	c := newTestClient(t, s)
+
+	// BTC falls back to its history, the other assets are unaffected.
+	btc, ok := c.GetCurrentVPI("BTC")
+	require.True(t, ok)
+	require.Equal(t, int64(1000), btc.Timestamp)
+	ltc, ok := c.GetCurrentVPI("LTC")
+	require.True(t, ok)
+	require.Equal(t, "2", ltc.K.String())
+	require.Equal(t, []validation.Violation{{
+		Rule:     validation.RuleParseableNumbers,
+		Severity: validation.SeverityWarning,
+		Subject:  "asset config BTC",
+		Asset:    "BTC",
+		Message:  "vpi skipped: vpi marketDepthShort is empty",
+	}}, c.GetValidationWarnings())
+
+	// Malformed numbers are still rejected by the ParseableNumbers validator.
+	s.set("/assets-config", []*types.AssetConfig{
+		{Index: 0, Name: "BTC", VPI: types.VPIParams{MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1.5", K: "3"}},
+	})
+	_, err := New(s.uri())
+	require.EqualError(t, err, `validate config: 1 config violations: parseable-numbers: asset config BTC: vpi.spread "1.5" is not an integer`)
+}
+
+func TestVPIHistoryParseErrorNamesAssetAndField(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/vpi-history", map[string]map[string]types.VPIParams{
+		"BTC": {"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "x"}},
+	})
+	_, err := New(s.uri())
+	require.EqualError(t, err, `parse vpi history of BTC@1000: vpi k "x" is not an integer`)
+}
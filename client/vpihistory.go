@@ -0,0 +1,274 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/validation"
+)
+
+const (
+	// VPIHistorySinceHeader is echoed back with the since query parameter by
+	// servers that only returned entries newer than it.
+	VPIHistorySinceHeader = "X-VPI-History-Since"
+	// VPIHistoryRewrittenHeader is set by the server after past entries were
+	// changed or removed. The client then drops its history and reloads it.
+	VPIHistoryRewrittenHeader = "X-VPI-History-Rewritten"
+)
+
+type rawVPIHistory = map[string]map[string]rawVPIParams
+
+// rawVPIParams decodes history entries without the unknown field bookkeeping
+// of types.VPIParams, which would only slow down decoding entries that are
+// dropped once parsed.
+type rawVPIParams struct {
+	MarketDepthLong  string `json:"marketDepthLong"`
+	MarketDepthShort string `json:"marketDepthShort"`
+	Spread           string `json:"spread"`
+	K                string `json:"k"`
+}
+
+func (p rawVPIParams) params() types.VPIParams {
+	return types.VPIParams{MarketDepthLong: p.MarketDepthLong, MarketDepthShort: p.MarketDepthShort, Spread: p.Spread, K: p.K}
+}
+
+// vpiHistory is the outcome of fetching /vpi-history for one refresh.
+type vpiHistory struct {
+	entries map[string]map[int64]types.VPIParamsParsed
+	// retainedFrom holds, per asset that lost entries to retention, the
+	// timestamp of the oldest entry kept.
+	retainedFrom map[string]int64
+	skipped      []vpiEntryKey
+}
+
+// vpiEntryKey identifies a history entry skipped for lacking market depths.
+type vpiEntryKey struct {
+	asset     string
+	timestamp int64
+}
+
+func (h *vpiHistory) warnings() []validation.Violation {
+	out := make([]validation.Violation, 0, len(h.skipped))
+	for _, k := range h.skipped {
+		out = append(out, validation.Violation{
+			Rule:     validation.RuleParseableNumbers,
+			Severity: validation.SeverityWarning,
+			Subject:  fmt.Sprintf("vpi history %s@%d", k.asset, k.timestamp),
+			Asset:    k.asset,
+			Message:  "entry skipped: market depth is empty",
+		})
+	}
+	return out
+}
+
+// fetchVPIHistory downloads the history in full on the first refresh and only
+// the entries newer than the known ones afterwards. A single since can't cover
+// the past entries of a newly listed asset, so the history is downloaded in
+// full again whenever listed names an asset the known history wasn't fetched
+// for. Responses are merged into the known history by asset and timestamp,
+// whether or not the server echoed since; the history is only replaced when
+// the server reports it was rewritten.
+func (c *configDiscovery) fetchVPIHistory(listed map[string]bool) (*vpiHistory, error) {
+	uri := c.cfgUri + "/vpi-history"
+	since, ok := vpiHistorySince(c.VPIHistorySorted, listed)
+	if c.fullVPIHistory || !ok {
+		return c.fetchFullVPIHistory(uri)
+	}
+	for name := range listed {
+		if !c.vpiListed[name] {
+			log.Info().Str("asset", name).Msg("new asset listed, reloading vpi history")
+			return c.fetchFullVPIHistory(uri)
+		}
+	}
+
+	sinceParam := strconv.FormatInt(since, 10)
+	resp, err := c.fetchResponse(uri + "?since=" + sinceParam)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch vpi history")
+	}
+	var history rawVPIHistory
+	if err := json.Unmarshal(resp.Body, &history); err != nil {
+		return nil, errors.Wrap(err, "unmarshal vpi history")
+	}
+
+	rewritten, _ := strconv.ParseBool(resp.Header.Get(VPIHistoryRewrittenHeader))
+	echoed := resp.Header.Get(VPIHistorySinceHeader)
+	switch {
+	case rewritten && echoed == "":
+		log.Warn().Msg("vpi history was rewritten, reparsing it")
+		return c.parseVPIHistory(history)
+	case rewritten || (echoed != "" && echoed != sinceParam):
+		log.Warn().Str("since", sinceParam).Str("echoed", echoed).Msg("vpi history was rewritten, reloading it")
+		return c.fetchFullVPIHistory(uri)
+	}
+
+	delta, err := c.parseVPIHistory(history)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Str("since", sinceParam).Int("assets", len(delta.entries)).Msg("merging vpi history")
+	merged := &vpiHistory{
+		entries:      mergeVPIHistory(c.VPIHistory, delta.entries),
+		retainedFrom: make(map[string]int64, len(c.vpiRetainedFrom)),
+		skipped:      mergeSkippedVPI(c.vpiSkipped, delta.skipped),
+	}
+	for name, ts := range c.vpiRetainedFrom {
+		merged.retainedFrom[name] = ts
+	}
+	c.pruneVPIHistory(merged)
+	return merged, nil
+}
+
+func (c *configDiscovery) fetchFullVPIHistory(uri string) (*vpiHistory, error) {
+	history, err := fetch[rawVPIHistory](c, uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch vpi history")
+	}
+	return c.parseVPIHistory(history)
+}
+
+// vpiHistorySince returns the oldest of the newest timestamps of the listed
+// assets, so that a single since parameter covers every one of them. Delisted
+// assets are left out, as their history no longer grows.
+func vpiHistorySince(sorted map[string][]types.VPIParamsParsed, listed map[string]bool) (int64, bool) {
+	var since int64
+	found := false
+	for name, entries := range sorted {
+		if len(entries) == 0 || !listed[name] {
+			continue
+		}
+		if last := entries[len(entries)-1].Timestamp; !found || last < since {
+			since, found = last, true
+		}
+	}
+	return since, found
+}
+
+// parseVPIHistory converts the raw /vpi-history payload. Entries without market
+// depths are skipped and reported as warnings. Entries outside the retention
+// are never parsed.
+func (c *configDiscovery) parseVPIHistory(history rawVPIHistory) (*vpiHistory, error) {
+	parsed := &vpiHistory{
+		entries:      make(map[string]map[int64]types.VPIParamsParsed, len(history)),
+		retainedFrom: make(map[string]int64),
+	}
+	now := c.clock.Now()
+	for name, h := range history {
+		var timestamps []int64
+		var skipped []int64
+		byTimestamp := make(map[int64]rawVPIParams, len(h))
+		for ts, params := range h {
+			timestamp, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse vpi history of %s: timestamp %q", name, ts)
+			}
+			if params.MarketDepthLong == "" || params.MarketDepthShort == "" {
+				skipped = append(skipped, timestamp)
+				continue
+			}
+			timestamps = append(timestamps, timestamp)
+			byTimestamp[timestamp] = params
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		start := c.vpiRetention.start(timestamps, now)
+		if start > 0 {
+			parsed.retainedFrom[name] = timestamps[start]
+		}
+		parsed.entries[name] = make(map[int64]types.VPIParamsParsed, len(timestamps)-start)
+		for _, timestamp := range timestamps[start:] {
+			p, err := byTimestamp[timestamp].params().Parse()
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse vpi history of %s@%d", name, timestamp)
+			}
+			p.Timestamp = timestamp
+			parsed.entries[name][timestamp] = p
+		}
+		for _, timestamp := range skipped {
+			if from, ok := parsed.retainedFrom[name]; !ok || timestamp >= from {
+				parsed.skipped = append(parsed.skipped, vpiEntryKey{asset: name, timestamp: timestamp})
+			}
+		}
+	}
+	sortVPIEntryKeys(parsed.skipped)
+	return parsed, nil
+}
+
+// pruneVPIHistory applies the retention to merged entries in place.
+func (c *configDiscovery) pruneVPIHistory(h *vpiHistory) {
+	now := c.clock.Now()
+	for name, entries := range h.entries {
+		timestamps := make([]int64, 0, len(entries))
+		for ts := range entries {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+		start := c.vpiRetention.start(timestamps, now)
+		if start == 0 {
+			continue
+		}
+		for _, ts := range timestamps[:start] {
+			delete(entries, ts)
+		}
+		h.retainedFrom[name] = timestamps[start]
+	}
+
+	skipped := make([]vpiEntryKey, 0, len(h.skipped))
+	for _, k := range h.skipped {
+		if from, ok := h.retainedFrom[k.asset]; ok && k.timestamp < from {
+			continue
+		}
+		skipped = append(skipped, k)
+	}
+	h.skipped = skipped
+}
+
+// mergeVPIHistory overlays delta on a copy of known, leaving known untouched
+// in case the refresh is rejected later on.
+func mergeVPIHistory(known, delta map[string]map[int64]types.VPIParamsParsed) map[string]map[int64]types.VPIParamsParsed {
+	merged := make(map[string]map[int64]types.VPIParamsParsed, len(known)+len(delta))
+	for name, h := range known {
+		merged[name] = make(map[int64]types.VPIParamsParsed, len(h)+len(delta[name]))
+		for ts, p := range h {
+			merged[name][ts] = p
+		}
+	}
+	for name, h := range delta {
+		if merged[name] == nil {
+			merged[name] = make(map[int64]types.VPIParamsParsed, len(h))
+		}
+		for ts, p := range h {
+			merged[name][ts] = p
+		}
+	}
+	return merged
+}
+
+func mergeSkippedVPI(known, delta []vpiEntryKey) []vpiEntryKey {
+	merged := make([]vpiEntryKey, 0, len(known)+len(delta))
+	seen := make(map[vpiEntryKey]bool, len(known)+len(delta))
+	for _, list := range [][]vpiEntryKey{known, delta} {
+		for _, k := range list {
+			if !seen[k] {
+				seen[k] = true
+				merged = append(merged, k)
+			}
+		}
+	}
+	sortVPIEntryKeys(merged)
+	return merged
+}
+
+func sortVPIEntryKeys(keys []vpiEntryKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].asset != keys[j].asset {
+			return keys[i].asset < keys[j].asset
+		}
+		return keys[i].timestamp < keys[j].timestamp
+	})
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func refreshVPIHistory(t *testing.T, s *testServer, c *configDiscovery, composedAt string, history map[string]map[string]types.VPIParams) {
+	s.set("", testAppConfig(composedAt))
+	s.set("/vpi-history", history)
+	require.NoError(t, c.FetchConfig())
+}
+
+func TestVPIHistoryMergedByTimestampWhenSinceIsNotEchoed(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+	require.Equal(t, []string{"/config/vpi-history"}, s.requested("/vpi-history"))
+
+	// The server ignores since and sends everything.
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {
+			"1000": {MarketDepthLong: "150", MarketDepthShort: "200", Spread: "1", K: "2"},
+			"2000": {MarketDepthLong: "300", MarketDepthShort: "400", Spread: "1", K: "2"},
+		},
+		"LTC": {"1500": {MarketDepthLong: "10", MarketDepthShort: "20", Spread: "1", K: "2"}},
+	})
+	require.Equal(t, "/config/vpi-history?since=1000", s.requested("/vpi-history")[1])
+	require.Equal(t, []int64{1000, 2000}, timestamps(c.VPIHistorySorted["BTC"]))
+	require.Equal(t, []int64{1500}, timestamps(c.VPIHistorySorted["LTC"]))
+	// Entries sent again replace the known ones with the same timestamp.
+	require.Equal(t, "150", c.VPIHistory["BTC"][1000].MarketDepthLong.String())
+
+	// The server honours since without echoing it: older entries are kept.
+	refreshVPIHistory(t, s, c, "2024-01-03T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {"3000": {MarketDepthLong: "500", MarketDepthShort: "600", Spread: "1", K: "2"}},
+	})
+	require.Equal(t, "/config/vpi-history?since=1500", s.requested("/vpi-history")[2])
+	require.Equal(t, []int64{1000, 2000, 3000}, timestamps(c.VPIHistorySorted["BTC"]))
+	require.Equal(t, []int64{1500}, timestamps(c.VPIHistorySorted["LTC"]))
+}
+
+func TestVPIHistorySinceIgnoresDelistedAssets(t *testing.T) {
+	s := newTestServer(t)
+	s.set("/vpi-history", map[string]map[string]types.VPIParams{
+		"BTC": {"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"}},
+		"XRP": {"10": {MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1", K: "2"}},
+	})
+	c := newTestClient(t, s)
+
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "1000")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{})
+	require.Equal(t, "/config/vpi-history?since=1000", s.requested("/vpi-history")[1])
+	require.Equal(t, []int64{10}, timestamps(c.VPIHistorySorted["XRP"]))
+}
+
+func TestVPIHistoryDeltaWhenSinceIsSupported(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "1000")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {
+			"2000": {MarketDepthLong: "300", MarketDepthShort: "400", Spread: "1", K: "2"},
+			"2500": {Spread: "1", K: "2"},
+		},
+		"LTC": {"1500": {MarketDepthLong: "10", MarketDepthShort: "20", Spread: "1", K: "2"}},
+	})
+
+	require.Equal(t, []int64{1000, 2000}, timestamps(c.VPIHistorySorted["BTC"]))
+	require.Equal(t, []int64{1500}, timestamps(c.VPIHistorySorted["LTC"]))
+	require.Len(t, c.GetValidationWarnings(), 1)
+	require.Equal(t, "vpi history BTC@2500", c.GetValidationWarnings()[0].Subject)
+
+	// The skipped entry is still reported after a delta that no longer carries it.
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "1500")
+	refreshVPIHistory(t, s, c, "2024-01-03T00:00:00Z", map[string]map[string]types.VPIParams{
+		"LTC": {"1600": {MarketDepthLong: "10", MarketDepthShort: "20", Spread: "1", K: "2"}},
+	})
+	require.Equal(t, "/config/vpi-history?since=1500", s.requested("/vpi-history")[2])
+	require.Equal(t, []int64{1000, 2000}, timestamps(c.VPIHistorySorted["BTC"]))
+	require.Equal(t, []int64{1500, 1600}, timestamps(c.VPIHistorySorted["LTC"]))
+	require.Len(t, c.GetValidationWarnings(), 1)
+}
+
+func TestVPIHistoryReloadedWhenAssetIsListed(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+
+	// SOL is listed with history older than the since the client would send.
+	s.set("/assets", []*types.Asset{
+		{Name: "BTC", Index: 0, Type: "crypto"},
+		{Name: "LTC", Index: 11, Type: "crypto"},
+		{Name: "SOL", Index: 12, Type: "crypto"},
+	})
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "1000")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {"1000": {MarketDepthLong: "100", MarketDepthShort: "200", Spread: "1", K: "2"}},
+		"SOL": {"500": {MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1", K: "2"}},
+	})
+	require.Equal(t, []string{"/config/vpi-history", "/config/vpi-history"}, s.requested("/vpi-history"))
+	require.Equal(t, []int64{500}, timestamps(c.VPIHistorySorted["SOL"]))
+
+	// Once the history covers SOL, deltas are requested again.
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "500")
+	refreshVPIHistory(t, s, c, "2024-01-03T00:00:00Z", map[string]map[string]types.VPIParams{})
+	require.Equal(t, "/config/vpi-history?since=500", s.requested("/vpi-history")[2])
+	require.Equal(t, []int64{500}, timestamps(c.VPIHistorySorted["SOL"]))
+}
+
+func TestVPIHistoryReloadedWhenRewritten(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "1000")
+	s.setHeader("/vpi-history", VPIHistoryRewrittenHeader, "true")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {"500": {MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1", K: "2"}},
+	})
+
+	require.Equal(t, []string{"/config/vpi-history", "/config/vpi-history?since=1000", "/config/vpi-history"}, s.requested("/vpi-history"))
+	require.Equal(t, []int64{500}, timestamps(c.VPIHistorySorted["BTC"]))
+}
+
+func TestVPIHistoryReloadedWhenSinceIsNotEchoed(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s)
+
+	s.setHeader("/vpi-history", VPIHistorySinceHeader, "0")
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {"500": {MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1", K: "2"}},
+	})
+
+	require.Len(t, s.requested("/vpi-history"), 3)
+	require.Equal(t, []int64{500}, timestamps(c.VPIHistorySorted["BTC"]))
+}
+
+func TestWithFullVPIHistory(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s, WithFullVPIHistory())
+
+	refreshVPIHistory(t, s, c, "2024-01-02T00:00:00Z", map[string]map[string]types.VPIParams{
+		"BTC": {"2000": {MarketDepthLong: "300", MarketDepthShort: "400", Spread: "1", K: "2"}},
+	})
+
+	require.Equal(t, []string{"/config/vpi-history", "/config/vpi-history"}, s.requested("/vpi-history"))
+	require.Equal(t, []int64{2000}, timestamps(c.VPIHistorySorted["BTC"]))
+}
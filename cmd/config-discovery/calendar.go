@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/storm-trade/config-discovery-client/client"
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+	"golang.org/x/exp/maps"
+)
+
+func runCalendar(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("calendar", flag.ExitOnError)
+	url := fs.String("url", os.Getenv("CONFIG_DISCOVERY_URL"), "config discovery service url")
+	asset := fs.String("asset", "", "asset name, every scheduled asset when empty")
+	from := fs.String("from", "", "first day, YYYY-MM-DD, today when empty")
+	tz := fs.String("tz", "", "time zone of the days, each asset's schedule time zone when empty")
+	days := fs.Int("days", 30, "number of days to export")
+	format := fs.String("format", "ics", "output format: ics or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return errors.New("-url is required")
+	}
+	var loc *time.Location
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return errors.Wrap(err, "parse -tz")
+		}
+	}
+	if *from != "" {
+		if _, err := time.Parse(time.DateOnly, *from); err != nil {
+			return errors.Wrap(err, "parse -from")
+		}
+	}
+
+	cfg, err := client.New(*url)
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+
+	names := []string{*asset}
+	if *asset == "" {
+		names = maps.Keys(cfg.GetResolvedSchedules())
+		sort.Strings(names)
+	}
+
+	var sessions []schedule.CalendarSession
+	for _, name := range names {
+		start, err := firstDay(*from, calendarLocation(loc, cfg.GetResolvedSchedule(name)))
+		if err != nil {
+			return errors.Wrap(err, "parse -from")
+		}
+		s, err := cfg.GetCalendar(name, start, start.AddDate(0, 0, *days))
+		if err != nil && *asset == "" {
+			log.Warn().Err(err).Str("asset", name).Msg("skipping asset")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, s...)
+	}
+
+	switch *format {
+	case "ics":
+		return schedule.WriteCalendarICS(w, sessions, time.Now())
+	case "json":
+		return schedule.WriteCalendarJSON(w, sessions)
+	default:
+		return errors.Errorf("unknown format %q", *format)
+	}
+}
+
+// calendarLocation returns the location days are counted in: loc when set,
+// otherwise the time zone of the asset's schedule.
+func calendarLocation(loc *time.Location, s *types.AssetSchedule) *time.Location {
+	if loc != nil {
+		return loc
+	}
+	if s != nil {
+		if l, err := time.LoadLocation(s.ScheduleTimeZone); err == nil {
+			return l
+		}
+	}
+	return time.UTC
+}
+
+// firstDay returns the start of the day named by from in loc, or of today when
+// from is empty.
+func firstDay(from string, loc *time.Location) (time.Time, error) {
+	if from == "" {
+		from = time.Now().In(loc).Format(time.DateOnly)
+	}
+	return time.ParseInLocation(time.DateOnly, from, loc)
+}
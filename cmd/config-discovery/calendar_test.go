@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+const evenings = "18:00-20:00|18:00-20:00|18:00-20:00|18:00-20:00|18:00-20:00|-|-"
+
+// newTestServer serves a config with the informational SPCX schedule, AAPL
+// scheduled by its asset config only, and the given vpi history.
+func newTestServer(t *testing.T, history map[string]map[string]types.VPIParams) string {
+	resources := map[string]any{
+		"/config":        types.AppConfig{ComposedAt: "2024-01-01T00:00:00Z"},
+		"/config/assets": []*types.Asset{{Name: "SPCX", Index: 0, Type: "stock"}, {Name: "AAPL", Index: 1, Type: "stock"}},
+		"/config/assets-schedule": types.AssetsSchedule{Schedules: map[string]*types.AssetSchedule{
+			"SPCX": {ScheduleTimeZone: "America/New_York", Schedule: evenings, ScheduleType: types.ScheduleTypeInfo},
+		}},
+		"/config/assets-config": []*types.AssetConfig{
+			{Name: "SPCX", Index: 0},
+			{Name: "AAPL", Index: 1, ScheduleTimeZone: "America/New_York", Schedule: evenings},
+		},
+		"/config/vpi-history": history,
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}))
+	t.Cleanup(s.Close)
+	return s.URL + "/config"
+}
+
+func runCalendarJSON(t *testing.T, args ...string) []schedule.CalendarSession {
+	var out bytes.Buffer
+	require.NoError(t, runCalendar(append(args, "-format", "json"), &out))
+	var sessions []schedule.CalendarSession
+	require.NoError(t, json.Unmarshal(out.Bytes(), &sessions))
+	return sessions
+}
+
+func TestCalendarCountsDaysInTheAssetTimeZone(t *testing.T) {
+	url := newTestServer(t, nil)
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	sessions := runCalendarJSON(t, "-url", url, "-asset", "SPCX", "-from", "2024-03-08", "-days", "1")
+	require.Len(t, sessions, 1)
+	require.True(t, time.Date(2024, 3, 8, 18, 0, 0, 0, ny).Equal(sessions[0].Open))
+	require.True(t, time.Date(2024, 3, 8, 20, 0, 0, 0, ny).Equal(sessions[0].Close))
+	require.True(t, sessions[0].Informational)
+
+	// In UTC the day starts at 19:00 the evening before in New York.
+	sessions = runCalendarJSON(t, "-url", url, "-asset", "SPCX", "-from", "2024-03-08", "-days", "1", "-tz", "UTC")
+	require.Len(t, sessions, 2)
+	require.True(t, time.Date(2024, 3, 7, 19, 0, 0, 0, ny).Equal(sessions[0].Open))
+	require.True(t, time.Date(2024, 3, 8, 19, 0, 0, 0, ny).Equal(sessions[1].Close))
+}
+
+func TestCalendarExportsEveryResolvedSchedule(t *testing.T) {
+	url := newTestServer(t, nil)
+
+	sessions := runCalendarJSON(t, "-url", url, "-from", "2024-03-08", "-days", "1")
+	var assets []string
+	for _, s := range sessions {
+		assets = append(assets, s.Asset)
+	}
+	require.Equal(t, []string{"AAPL", "SPCX"}, assets)
+	require.False(t, sessions[0].Informational)
+}
+
+func TestCalendarICSKeepsUIDsOfClippedSessions(t *testing.T) {
+	url := newTestServer(t, nil)
+
+	var out bytes.Buffer
+	require.NoError(t, runCalendar([]string{"-url", url, "-asset", "SPCX", "-from", "2024-03-08", "-days", "1", "-tz", "UTC"}, &out))
+	// Thursday's session opened at 18:00 New York time, before the exported range.
+	require.Contains(t, out.String(), "UID:SPCX-1709852400@config-discovery-client\r\n")
+	require.Contains(t, out.String(), "DTSTART:20240308T000000Z\r\n")
+	require.Contains(t, out.String(), "STATUS:TENTATIVE\r\n")
+}
+
+func TestCalendarRejectsMalformedFlags(t *testing.T) {
+	url := newTestServer(t, nil)
+	require.EqualError(t, runCalendar([]string{"-url", url, "-from", "08.03.2024"}, &bytes.Buffer{}),
+		`parse -from: parsing time "08.03.2024" as "2006-01-02": cannot parse "08.03.2024" as "2006"`)
+	require.EqualError(t, runCalendar([]string{"-url", url, "-tz", "Mars/Olympus_Mons"}, &bytes.Buffer{}),
+		"parse -tz: unknown time zone Mars/Olympus_Mons")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/client"
+)
+
+func runIntegrity(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("integrity", flag.ExitOnError)
+	url := fs.String("url", os.Getenv("CONFIG_DISCOVERY_URL"), "config discovery service url")
+	asJSON := fs.Bool("json", false, "print the report as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return errors.New("-url is required")
+	}
+
+	// The report is meant to show broken configs, so they must not be rejected.
+	cfg, err := client.New(*url, client.WithoutDefaultValidators())
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+
+	report := cfg.GetIntegrityReport()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.WriteText(w)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/validation"
+	"github.com/test-go/testify/require"
+)
+
+func TestIntegrityWritesReport(t *testing.T) {
+	url := newTestServer(t, nil)
+
+	var out bytes.Buffer
+	require.NoError(t, runIntegrity([]string{"-url", url}, &out))
+	require.Equal(t, "no integrity problems found\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runIntegrity([]string{"-url", url, "-json"}, &out))
+	var report validation.IntegrityReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.True(t, report.Empty())
+	require.Equal(t, []string{}, report.AssetsWithoutConfig)
+}
+
+func TestIntegrityRequiresURL(t *testing.T) {
+	t.Setenv("CONFIG_DISCOVERY_URL", "")
+	require.EqualError(t, runIntegrity(nil, &bytes.Buffer{}), "-url is required")
+}
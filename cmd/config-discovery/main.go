@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: config-discovery <command> [flags]
+
+commands:
+  integrity   report dangling references in the current config
+  calendar    export trading sessions as iCalendar or json
+  vpi-history export vpi history as csv or jsonl
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "integrity":
+		err = runIntegrity(os.Args[2:], os.Stdout)
+	case "calendar":
+		err = runCalendar(os.Args[2:], os.Stdout)
+	case "vpi-history":
+		err = runVPIHistory(os.Args[2:], os.Stdout)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
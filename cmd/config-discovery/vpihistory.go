@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/client"
+	"github.com/storm-trade/config-discovery-client/vpi"
+)
+
+func runVPIHistory(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("vpi-history", flag.ExitOnError)
+	url := fs.String("url", os.Getenv("CONFIG_DISCOVERY_URL"), "config discovery service url")
+	assets := fs.String("asset", "", "comma separated asset names, every asset when empty")
+	from := fs.String("from", "", "first timestamp: RFC 3339, YYYY-MM-DD (UTC) or unix milliseconds")
+	to := fs.String("to", "", "last timestamp: RFC 3339, YYYY-MM-DD (UTC, the whole day) or unix milliseconds")
+	format := fs.String("format", "csv", "output format: csv or jsonl")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return errors.New("-url is required")
+	}
+
+	var filter vpi.Filter
+	if *assets != "" {
+		filter.Assets = strings.Split(*assets, ",")
+	}
+	var err error
+	if filter.From, err = parseTimeFlag(*from, false); err != nil {
+		return errors.Wrap(err, "parse -from")
+	}
+	if filter.To, err = parseTimeFlag(*to, true); err != nil {
+		return errors.Wrap(err, "parse -to")
+	}
+
+	cfg, err := client.New(*url)
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+	records := vpi.Select(cfg.GetSnapshot().VPIHistory, filter)
+
+	switch *format {
+	case "csv":
+		return vpi.WriteCSV(w, records)
+	case "jsonl":
+		return vpi.WriteJSONL(w, records)
+	default:
+		return errors.Errorf("unknown format %q", *format)
+	}
+}
+
+// parseTimeFlag parses a -from or -to value. A date is a UTC day: its start,
+// or its last instant when endOfDay is set, since the filter bounds are
+// inclusive.
+func parseTimeFlag(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, v)
+	if err != nil || !endOfDay {
+		return day, err
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/storm-trade/config-discovery-client/vpi"
+	"github.com/test-go/testify/require"
+)
+
+func TestVPIHistoryDateBoundsCoverWholeDays(t *testing.T) {
+	params := types.VPIParams{MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1", K: "2"}
+	url := newTestServer(t, map[string]map[string]types.VPIParams{"SPCX": {
+		"1727740799000": params, // 2024-09-30T23:59:59Z
+		"1727740800000": params, // 2024-10-01T00:00:00Z
+		"1727827199999": params, // 2024-10-01T23:59:59.999Z
+		"1727827200000": params, // 2024-10-02T00:00:00Z
+	}})
+
+	var out bytes.Buffer
+	require.NoError(t, runVPIHistory([]string{"-url", url, "-from", "2024-10-01", "-to", "2024-10-01", "-format", "jsonl"}, &out))
+	var timestamps []int64
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r vpi.Record
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		timestamps = append(timestamps, r.TimestampMs)
+	}
+	require.Equal(t, []int64{1727740800000, 1727827199999}, timestamps)
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		endOfDay bool
+		want     string
+	}{
+		{"2024-10-01", false, "2024-10-01T00:00:00Z"},
+		{"2024-10-01", true, "2024-10-01T23:59:59.999999999Z"},
+		{"2024-10-01T12:00:00+02:00", true, "2024-10-01T10:00:00Z"},
+		{"1727784000000", true, "2024-10-01T12:00:00Z"},
+	} {
+		got, err := parseTimeFlag(tc.value, tc.endOfDay)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got.UTC().Format("2006-01-02T15:04:05.999999999Z07:00"), tc.value)
+	}
+}
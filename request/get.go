@@ -7,25 +7,47 @@ import (
 	"net/http"
 )
 
-func Get[T any](uri string) (T, error) {
-	var result T
+type Response struct {
+	Body   []byte
+	Header http.Header
+}
+
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
 
+func Fetch(uri string) (*Response, error) {
 	resp, err := http.Get(uri)
 	if err != nil {
-		return result, fmt.Errorf("failed to fetch url: %w", err)
+		return nil, fmt.Errorf("failed to fetch url: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return result, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return &Response{Body: body, Header: resp.Header}, nil
+}
+
+func Get[T any](uri string) (T, error) {
+	var result T
+
+	resp, err := Fetch(uri)
+	if err != nil {
+		return result, err
 	}
 
-	err = json.Unmarshal(body, &result)
+	err = json.Unmarshal(resp.Body, &result)
 	if err != nil {
 		return result, fmt.Errorf("failed to unmarshal json: %w", err)
 	}
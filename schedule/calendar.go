@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// CalendarSession is a session of one asset. Informational sessions come from
+// schedules of type "info" and are not enforced by trading.
+type CalendarSession struct {
+	Asset         string    `json:"asset"`
+	Open          time.Time `json:"open"`
+	Close         time.Time `json:"close"`
+	TimeZone      string    `json:"timeZone"`
+	Informational bool      `json:"informational"`
+
+	// start is the open of a session clipped to the exported range, so that a
+	// session keeps its UID whatever range it is exported with.
+	start time.Time
+}
+
+func (s CalendarSession) uid() string {
+	start := s.start
+	if start.IsZero() {
+		start = s.Open
+	}
+	return fmt.Sprintf("%s-%d@config-discovery-client", s.Asset, start.Unix())
+}
+
+// Calendar returns the sessions of an asset overlapping [from, to).
+func Calendar(asset string, s *Schedule, scheduleType types.ScheduleType, from, to time.Time) []CalendarSession {
+	var out []CalendarSession
+	for _, session := range s.Sessions(from, to) {
+		cs := CalendarSession{
+			Asset:         asset,
+			Open:          session.Open.In(s.Location),
+			Close:         session.Close.In(s.Location),
+			TimeZone:      s.Location.String(),
+			Informational: !scheduleType.IsEffective(),
+		}
+		if s.AlwaysOpen {
+			// A market that never closes has a single session without a start.
+			cs.start = time.Unix(0, 0)
+		} else if full, ok := s.CurrentSession(session.Open); ok && full.Open.Before(session.Open) {
+			cs.start = full.Open
+		}
+		out = append(out, cs)
+	}
+	return out
+}
+
+func WriteCalendarJSON(w io.Writer, sessions []CalendarSession) error {
+	if sessions == nil {
+		sessions = []CalendarSession{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sessions)
+}
+
+// WriteCalendarICS writes sessions as an RFC 5545 calendar. stamp is used for
+// DTSTAMP so that the output is reproducible.
+func WriteCalendarICS(w io.Writer, sessions []CalendarSession, stamp time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Storm Trade//config-discovery-client//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	for _, s := range sessions {
+		summary := s.Asset + " trading session"
+		description := fmt.Sprintf("%s trades from %s to %s (%s).", s.Asset,
+			s.Open.Format("2006-01-02 15:04"), s.Close.Format("2006-01-02 15:04"), s.TimeZone)
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+s.uid(),
+			"DTSTAMP:"+stamp.UTC().Format(icsTimeLayout),
+			"DTSTART:"+s.Open.UTC().Format(icsTimeLayout),
+			"DTEND:"+s.Close.UTC().Format(icsTimeLayout),
+		)
+		if s.Informational {
+			summary += " (informational)"
+			description += " This schedule is informational and is not enforced."
+			lines = append(lines, "STATUS:TENTATIVE", "TRANSP:TRANSPARENT", "CATEGORIES:INFORMATIONAL")
+		} else {
+			lines = append(lines, "STATUS:CONFIRMED", "TRANSP:OPAQUE", "CATEGORIES:TRADING")
+		}
+		lines = append(lines,
+			"SUMMARY:"+escapeText(summary),
+			"DESCRIPTION:"+escapeText(description),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// fold splits content lines longer than 75 octets as required by RFC 5545,
+// never inside a multi-byte character.
+func fold(line string) string {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	return b.String()
+}
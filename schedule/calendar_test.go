@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestCalendarSessions(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities, Holidays: `["2024-01-15"]`})
+
+	sessions := Calendar("SPCX", s, types.ScheduleTypeInfo, time.Date(2024, 1, 12, 0, 0, 0, 0, ny), time.Date(2024, 1, 17, 0, 0, 0, 0, ny))
+	require.Equal(t, []CalendarSession{
+		{Asset: "SPCX", Open: time.Date(2024, 1, 12, 9, 30, 0, 0, ny), Close: time.Date(2024, 1, 12, 16, 0, 0, 0, ny), TimeZone: "America/New_York", Informational: true},
+		{Asset: "SPCX", Open: time.Date(2024, 1, 16, 9, 30, 0, 0, ny), Close: time.Date(2024, 1, 16, 16, 0, 0, 0, ny), TimeZone: "America/New_York", Informational: true},
+	}, sessions)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCalendarJSON(&out, sessions))
+	var decoded []CalendarSession
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	require.Contains(t, out.String(), `"open": "2024-01-12T09:30:00-05:00"`)
+
+	out.Reset()
+	require.NoError(t, WriteCalendarJSON(&out, nil))
+	require.Equal(t, "[]\n", out.String())
+}
+
+func TestCalendarICS(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities})
+	from := time.Date(2024, 3, 8, 0, 0, 0, 0, ny)
+	to := time.Date(2024, 3, 12, 0, 0, 0, 0, ny)
+
+	var out bytes.Buffer
+	sessions := append(Calendar("LTC", s, types.ScheduleTypeEffective, from, to), Calendar("SPCX", s, types.ScheduleTypeInfo, from, to)...)
+	require.NoError(t, WriteCalendarICS(&out, sessions, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+	ics := out.String()
+	require.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	require.True(t, strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	require.Equal(t, 4, strings.Count(ics, "BEGIN:VEVENT"))
+	require.Contains(t, ics, "UID:LTC-1709908200@config-discovery-client\r\nDTSTAMP:20240301T000000Z\r\nDTSTART:20240308T143000Z\r\nDTEND:20240308T210000Z\r\nSTATUS:CONFIRMED\r\n")
+	require.Contains(t, ics, "DTSTART:20240311T133000Z\r\nDTEND:20240311T200000Z\r\n")
+	require.Contains(t, ics, "SUMMARY:SPCX trading session (informational)\r\n")
+	require.Equal(t, 2, strings.Count(ics, "CATEGORIES:INFORMATIONAL"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		require.True(t, len(line) <= 75, line)
+	}
+}
+
+func TestCalendarUIDIgnoresClipping(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities})
+	stamp := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	uids := func(from time.Time) []string {
+		var out bytes.Buffer
+		require.NoError(t, WriteCalendarICS(&out, Calendar("LTC", s, types.ScheduleTypeEffective, from, time.Date(2024, 3, 9, 0, 0, 0, 0, ny)), stamp))
+		var uids []string
+		for _, line := range strings.Split(out.String(), "\r\n") {
+			if strings.HasPrefix(line, "UID:") {
+				uids = append(uids, line)
+			}
+		}
+		return uids
+	}
+	require.Equal(t, []string{"UID:LTC-1709908200@config-discovery-client"}, uids(time.Date(2024, 3, 8, 0, 0, 0, 0, ny)))
+	require.Equal(t, []string{"UID:LTC-1709908200@config-discovery-client"}, uids(time.Date(2024, 3, 8, 12, 0, 0, 0, ny)))
+
+	always := mustSchedule(t, types.AssetSchedule{})
+	sessions := Calendar("BTC", always, types.ScheduleTypeEffective, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC))
+	require.Len(t, sessions, 1)
+	require.Equal(t, "BTC-0@config-discovery-client", sessions[0].uid())
+}
+
+func TestFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := fold(line)
+	for _, part := range strings.Split(folded, "\r\n") {
+		require.True(t, len(part) <= 75, part)
+	}
+	require.Equal(t, line, strings.ReplaceAll(folded, "\r\n ", ""))
+	require.Equal(t, `a\;b\,c\\d\ne`, escapeText("a;b,c\\d\ne"))
+}
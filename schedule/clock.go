@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Timer interface {
+	Stop() bool
+}
+
+// Clock abstracts time so that schedule driven behaviour can be tested.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var SystemClock Clock = systemClock{}
+
+// ManualClock only moves when Advance is called, firing due timers in order.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	f        func()
+	done     bool
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{clock: c, deadline: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		pending := c.timers[:0]
+		for _, t := range c.timers {
+			if !t.done {
+				pending = append(pending, t)
+			}
+		}
+		c.timers = pending
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+		var next *manualTimer
+		for _, t := range c.timers {
+			if !t.deadline.After(target) {
+				next = t
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.done = true
+		if next.deadline.After(c.now) {
+			c.now = next.deadline
+		}
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	stopped := !t.done
+	t.done = true
+	return stopped
+}
@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventOpen         EventType = "open"
+	EventClose        EventType = "close"
+	EventHolidayStart EventType = "holiday-start"
+)
+
+type Event struct {
+	Asset string    `json:"asset"`
+	Type  EventType `json:"type"`
+	At    time.Time `json:"at"`
+}
+
+// NextEvents returns every transition happening at the earliest boundary
+// strictly after t.
+func (s *Schedule) NextEvents(t time.Time) []Event {
+	var events []Event
+	add := func(typ EventType, at time.Time, err error) {
+		if err != nil {
+			return
+		}
+		if len(events) > 0 && at.Before(events[0].At) {
+			events = events[:0]
+		}
+		if len(events) == 0 || at.Equal(events[0].At) {
+			events = append(events, Event{Type: typ, At: at})
+		}
+	}
+
+	open, err := s.NextOpen(t)
+	add(EventOpen, open, err)
+	closeAt, err := s.NextClose(t)
+	add(EventClose, closeAt, err)
+	holiday, err := s.nextHolidayStart(t)
+	add(EventHolidayStart, holiday, err)
+	return events
+}
+
+func (s *Schedule) nextHolidayStart(t time.Time) (time.Time, error) {
+	dates := make([]string, 0, len(s.Holidays))
+	for date := range s.Holidays {
+		if _, ok := s.NonHolidays[date]; !ok {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		start, err := time.ParseInLocation(dateLayout, date, s.Location)
+		if err != nil {
+			continue
+		}
+		if start.After(t) {
+			return start, nil
+		}
+	}
+	return time.Time{}, ErrNoTransition
+}
+
+// Notifier arms one timer per asset for its next transition and calls the
+// handler when it fires. Reset replaces the schedules and re-arms every timer.
+type Notifier struct {
+	clock   Clock
+	handler func(Event)
+
+	mu         sync.Mutex
+	generation int
+	timers     map[string]Timer
+}
+
+func NewNotifier(clock Clock, handler func(Event)) *Notifier {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &Notifier{clock: clock, handler: handler, timers: map[string]Timer{}}
+}
+
+func (n *Notifier) Reset(schedules map[string]*Schedule) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.stopLocked()
+	now := n.clock.Now()
+	for name, s := range schedules {
+		n.armLocked(name, s, now)
+	}
+}
+
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopLocked()
+}
+
+func (n *Notifier) stopLocked() {
+	n.generation++
+	for name, t := range n.timers {
+		t.Stop()
+		delete(n.timers, name)
+	}
+}
+
+func (n *Notifier) armLocked(name string, s *Schedule, after time.Time) {
+	events := s.NextEvents(after)
+	if len(events) == 0 {
+		delete(n.timers, name)
+		return
+	}
+
+	generation := n.generation
+	n.timers[name] = n.clock.AfterFunc(events[0].At.Sub(n.clock.Now()), func() {
+		n.mu.Lock()
+		if generation != n.generation {
+			n.mu.Unlock()
+			return
+		}
+		n.armLocked(name, s, events[0].At)
+		n.mu.Unlock()
+
+		for _, e := range events {
+			e.Asset = name
+			n.handler(e)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) take() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.events
+	r.events = nil
+	return out
+}
+
+func TestNextEvents(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities, Holidays: `["2024-01-15"]`})
+
+	require.Equal(t, []Event{{Type: EventOpen, At: time.Date(2024, 1, 12, 9, 30, 0, 0, ny)}}, s.NextEvents(time.Date(2024, 1, 12, 8, 0, 0, 0, ny)))
+	require.Equal(t, []Event{{Type: EventClose, At: time.Date(2024, 1, 12, 16, 0, 0, 0, ny)}}, s.NextEvents(time.Date(2024, 1, 12, 9, 30, 0, 0, ny)))
+	require.Equal(t, []Event{{Type: EventHolidayStart, At: time.Date(2024, 1, 15, 0, 0, 0, 0, ny)}}, s.NextEvents(time.Date(2024, 1, 12, 16, 0, 0, 0, ny)))
+
+	alwaysOpen, err := Parse("", "UTC")
+	require.NoError(t, err)
+	require.Empty(t, alwaysOpen.NextEvents(time.Now()))
+}
+
+func TestNotifierEmitsTransitions(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities, Holidays: `["2024-01-15"]`})
+
+	clock := NewManualClock(time.Date(2024, 1, 12, 8, 0, 0, 0, ny))
+	r := &recorder{}
+	n := NewNotifier(clock, r.handle)
+	n.Reset(map[string]*Schedule{"LTC": s})
+
+	clock.Advance(time.Hour)
+	require.Empty(t, r.take())
+
+	clock.Advance(30 * time.Minute)
+	require.Equal(t, []Event{{Asset: "LTC", Type: EventOpen, At: time.Date(2024, 1, 12, 9, 30, 0, 0, ny)}}, r.take())
+
+	clock.Advance(4 * 24 * time.Hour)
+	require.Equal(t, []Event{
+		{Asset: "LTC", Type: EventClose, At: time.Date(2024, 1, 12, 16, 0, 0, 0, ny)},
+		{Asset: "LTC", Type: EventHolidayStart, At: time.Date(2024, 1, 15, 0, 0, 0, 0, ny)},
+		{Asset: "LTC", Type: EventOpen, At: time.Date(2024, 1, 16, 9, 30, 0, 0, ny)},
+	}, r.take())
+}
+
+func TestNotifierResetRearmsTimers(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	clock := NewManualClock(time.Date(2024, 1, 12, 8, 0, 0, 0, ny))
+	r := &recorder{}
+	n := NewNotifier(clock, r.handle)
+
+	n.Reset(map[string]*Schedule{"LTC": mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities})})
+	n.Reset(map[string]*Schedule{"LTC": mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York",
+		Schedule: "10:00-16:00|10:00-16:00|10:00-16:00|10:00-16:00|10:00-16:00|-|-"})})
+
+	clock.Advance(2 * time.Hour)
+	require.Equal(t, []Event{{Asset: "LTC", Type: EventOpen, At: time.Date(2024, 1, 12, 10, 0, 0, 0, ny)}}, r.take())
+
+	n.Stop()
+	clock.Advance(24 * time.Hour)
+	require.Empty(t, r.take())
+}
+
+func TestNotifierAroundTheClockWithHoliday(t *testing.T) {
+	christmas := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	s := mustSchedule(t, types.AssetSchedule{Holidays: `["2024-12-25"]`})
+
+	clock := NewManualClock(time.Date(2024, 10, 9, 12, 0, 0, 0, time.UTC))
+	r := &recorder{}
+	n := NewNotifier(clock, r.handle)
+	n.Reset(map[string]*Schedule{"BTC": s})
+	defer n.Stop()
+
+	for i := 0; i < 120; i++ {
+		clock.Advance(day)
+	}
+	require.Equal(t, []Event{
+		{Asset: "BTC", Type: EventClose, At: christmas},
+		{Asset: "BTC", Type: EventHolidayStart, At: christmas},
+		{Asset: "BTC", Type: EventOpen, At: christmas.Add(day)},
+	}, r.take())
+}
@@ -0,0 +1,90 @@
+package schedule
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+// Override replaces the weekly windows of one local date. Regular keeps the
+// weekly windows, otherwise Windows apply and an empty list closes the day.
+type Override struct {
+	Windows []Window
+	Regular bool
+}
+
+type overrideEntry struct {
+	Date     string `json:"date"`
+	Schedule string `json:"schedule"`
+}
+
+// ParseOverrides parses a Holidays or NonHolidays list. Entries are either a
+// date ("2024-12-25") or a date with the windows of that day
+// ({"date":"2024-11-29","schedule":"09:30-13:00"}). A bare date is a full
+// closure in Holidays and a regular trading day in NonHolidays. Lists that are
+// not JSON are read as comma separated dates.
+func ParseOverrides(list string, regular bool) (map[string]Override, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+
+	var raw []json.RawMessage
+	if strings.HasPrefix(list, "[") {
+		if err := json.Unmarshal([]byte(list), &raw); err != nil {
+			return nil, errors.Wrapf(ErrMalformed, "%q: %s", list, err)
+		}
+	} else {
+		for _, d := range strings.Split(list, ",") {
+			b, _ := json.Marshal(strings.TrimSpace(d))
+			raw = append(raw, b)
+		}
+	}
+
+	out := make(map[string]Override, len(raw))
+	for _, r := range raw {
+		var entry overrideEntry
+		if err := json.Unmarshal(r, &entry.Date); err != nil {
+			if err := json.Unmarshal(r, &entry); err != nil {
+				return nil, errors.Wrapf(ErrMalformed, "entry %s", r)
+			}
+		}
+		if _, err := time.Parse(dateLayout, entry.Date); err != nil {
+			return nil, errors.Wrapf(ErrMalformed, "entry %s: bad date %q", r, entry.Date)
+		}
+
+		if entry.Schedule == "" {
+			out[entry.Date] = Override{Regular: regular}
+			continue
+		}
+		windows, err := parseDay(entry.Schedule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "entry %s", r)
+		}
+		out[entry.Date] = Override{Windows: windows}
+	}
+	return out, nil
+}
+
+// windowsOn resolves the windows of the local date of d: NonHolidays win over
+// Holidays, which win over the weekly schedule.
+func (s *Schedule) windowsOn(d time.Time) []Window {
+	key := d.Format(dateLayout)
+	if o, ok := s.NonHolidays[key]; ok {
+		if o.Regular {
+			return s.Weekly[d.Weekday()]
+		}
+		return o.Windows
+	}
+	if o, ok := s.Holidays[key]; ok {
+		if o.Regular {
+			return s.Weekly[d.Weekday()]
+		}
+		return o.Windows
+	}
+	return s.Weekly[d.Weekday()]
+}
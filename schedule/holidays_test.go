@@ -0,0 +1,246 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+const londonEquities = "08:00-16:30|08:00-16:30|08:00-16:30|08:00-16:30|08:00-16:30|-|-"
+
+func mustSchedule(t *testing.T, a types.AssetSchedule) *Schedule {
+	s, err := FromAssetSchedule(&a)
+	require.NoError(t, err)
+	return s
+}
+
+func TestParseOverrides(t *testing.T) {
+	o, err := ParseOverrides(`["2024-12-25",{"date":"2024-11-29","schedule":"09:30-13:00"}]`, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]Override{
+		"2024-12-25": {},
+		"2024-11-29": {Windows: []Window{{Start: 9*time.Hour + 30*time.Minute, End: 13 * time.Hour}}},
+	}, o)
+
+	o, err = ParseOverrides("2024-12-25, 2024-12-26", true)
+	require.NoError(t, err)
+	require.Equal(t, map[string]Override{"2024-12-25": {Regular: true}, "2024-12-26": {Regular: true}}, o)
+
+	o, err = ParseOverrides("[]", false)
+	require.NoError(t, err)
+	require.Empty(t, o)
+
+	for _, bad := range []string{`["2024-13-01"]`, `[{"date":"2024-01-01","schedule":"9-10"}]`, `[1`, `[42]`} {
+		_, err = ParseOverrides(bad, false)
+		require.True(t, errors.Is(err, ErrMalformed), bad)
+	}
+}
+
+func TestNextOpenAndCloseAcrossDST(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	london := mustLocation(t, "Europe/London")
+	berlin := mustLocation(t, "Europe/Berlin")
+
+	usStocks := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities,
+		Holidays: `["2024-11-28",{"date":"2024-11-29","schedule":"09:30-13:00"}]`})
+	ukStocks := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "Europe/London", Schedule: londonEquities,
+		Holidays: `["2024-03-29","2024-04-01"]`})
+	deStocks := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "Europe/Berlin", Schedule: "09:00-17:30|09:00-17:30|09:00-17:30|09:00-17:30|09:00-17:30|-|-"})
+	futures := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/Chicago", Schedule: "18:00-17:00|18:00-17:00|18:00-17:00|18:00-17:00|-|-|18:00-17:00"})
+
+	for _, tc := range []struct {
+		name      string
+		schedule  *Schedule
+		at        time.Time
+		nextOpen  time.Time
+		nextClose time.Time
+	}{
+		{
+			name:      "US weekend into spring forward",
+			schedule:  usStocks,
+			at:        time.Date(2024, 3, 8, 17, 0, 0, 0, ny),
+			nextOpen:  time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 3, 11, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "US last session before spring forward",
+			schedule:  usStocks,
+			at:        time.Date(2024, 3, 8, 12, 0, 0, 0, ny),
+			nextOpen:  time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 3, 8, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "US weekend into fall back",
+			schedule:  usStocks,
+			at:        time.Date(2024, 11, 1, 16, 30, 0, 0, ny),
+			nextOpen:  time.Date(2024, 11, 4, 14, 30, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 11, 4, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "US holiday then shortened day",
+			schedule:  usStocks,
+			at:        time.Date(2024, 11, 27, 17, 0, 0, 0, ny),
+			nextOpen:  time.Date(2024, 11, 29, 9, 30, 0, 0, ny),
+			nextClose: time.Date(2024, 11, 29, 13, 0, 0, 0, ny),
+		},
+		{
+			name:      "UK Easter holidays across EU spring forward",
+			schedule:  ukStocks,
+			at:        time.Date(2024, 3, 28, 17, 0, 0, 0, london),
+			nextOpen:  time.Date(2024, 4, 2, 7, 0, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 4, 2, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "UK weekend into EU fall back",
+			schedule:  ukStocks,
+			at:        time.Date(2024, 10, 25, 17, 0, 0, 0, london),
+			nextOpen:  time.Date(2024, 10, 28, 8, 0, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 10, 28, 16, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "Germany weekend into EU spring forward",
+			schedule:  deStocks,
+			at:        time.Date(2024, 3, 29, 18, 0, 0, 0, berlin),
+			nextOpen:  time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 4, 1, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "overnight futures reopen after US fall back",
+			schedule:  futures,
+			at:        time.Date(2024, 11, 2, 12, 0, 0, 0, time.UTC),
+			nextOpen:  time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC),
+			nextClose: time.Date(2024, 11, 4, 23, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			open, err := tc.schedule.NextOpen(tc.at)
+			require.NoError(t, err)
+			require.True(t, tc.nextOpen.Equal(open), "next open %s, want %s", open.UTC(), tc.nextOpen.UTC())
+
+			closeAt, err := tc.schedule.NextClose(tc.at)
+			require.NoError(t, err)
+			require.True(t, tc.nextClose.Equal(closeAt), "next close %s, want %s", closeAt.UTC(), tc.nextClose.UTC())
+		})
+	}
+}
+
+func TestTimeUntilClose(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: "-|-|-|-|-|20:00-04:00|-",
+		Holidays: `[{"date":"2024-11-29","schedule":"09:30-13:00"}]`})
+
+	// The overnight session loses an hour to the spring forward.
+	d, err := s.TimeUntilClose(time.Date(2024, 3, 9, 20, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.Equal(t, 7*time.Hour, d)
+
+	d, err = s.TimeUntilClose(time.Date(2024, 11, 2, 20, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.Equal(t, 9*time.Hour, d)
+
+	d, err = s.TimeUntilClose(time.Date(2024, 11, 29, 12, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, d)
+
+	d, err = s.TimeUntilClose(time.Date(2024, 3, 6, 12, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.Zero(t, d)
+}
+
+func TestNonHolidays(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: usEquities,
+		Holidays:    `["2024-07-04","2024-07-05"]`,
+		NonHolidays: `["2024-07-05",{"date":"2024-07-06","schedule":"10:00-12:00"}]`})
+
+	require.False(t, s.IsOpen(time.Date(2024, 7, 4, 12, 0, 0, 0, ny)))
+	require.True(t, s.IsOpen(time.Date(2024, 7, 5, 12, 0, 0, 0, ny)))
+	require.True(t, s.IsOpen(time.Date(2024, 7, 6, 11, 0, 0, 0, ny)))
+	require.False(t, s.IsOpen(time.Date(2024, 7, 6, 12, 0, 0, 0, ny)))
+
+	open, err := s.NextOpen(time.Date(2024, 7, 5, 17, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.True(t, time.Date(2024, 7, 6, 10, 0, 0, 0, ny).Equal(open))
+}
+
+func TestAlwaysOpenHasNoTransitions(t *testing.T) {
+	s, err := Parse("", "UTC")
+	require.NoError(t, err)
+
+	_, err = s.NextOpen(time.Now())
+	require.True(t, errors.Is(err, ErrNoTransition))
+	_, err = s.NextClose(time.Now())
+	require.True(t, errors.Is(err, ErrNoTransition))
+	_, err = s.TimeUntilClose(time.Now())
+	require.True(t, errors.Is(err, ErrNoTransition))
+
+	closed, err := Parse("-|-|-|-|-|-|-", "UTC")
+	require.NoError(t, err)
+	_, err = closed.NextOpen(time.Now())
+	require.True(t, errors.Is(err, ErrNoTransition))
+}
+
+func TestAroundTheClockWithHoliday(t *testing.T) {
+	for _, schedule := range []string{"", "00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00"} {
+		s := mustSchedule(t, types.AssetSchedule{Schedule: schedule, Holidays: `["2024-12-25"]`})
+		christmas := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+		at := time.Date(2024, 10, 9, 12, 0, 0, 0, time.UTC)
+
+		require.True(t, s.IsOpen(at), schedule)
+		require.False(t, s.IsOpen(christmas.Add(12*time.Hour)), schedule)
+		require.True(t, s.IsOpen(christmas.Add(day)), schedule)
+
+		session, ok := s.CurrentSession(at)
+		require.True(t, ok)
+		require.True(t, christmas.Equal(session.Close), "close %s", session.Close)
+
+		closeAt, err := s.NextClose(at)
+		require.NoError(t, err)
+		require.True(t, christmas.Equal(closeAt), "next close %s", closeAt)
+
+		open, err := s.NextOpen(at)
+		require.NoError(t, err)
+		require.True(t, christmas.Add(day).Equal(open), "next open %s", open)
+
+		d, err := s.TimeUntilClose(at)
+		require.NoError(t, err)
+		require.Equal(t, christmas.Sub(at), d)
+
+		// No gap is left after the holiday.
+		_, err = s.NextClose(christmas.Add(day))
+		require.True(t, errors.Is(err, ErrNoTransition))
+		_, err = s.NextOpen(christmas.Add(day))
+		require.True(t, errors.Is(err, ErrNoTransition))
+	}
+}
+
+func TestHolidayOnlySchedule(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+	s := mustSchedule(t, types.AssetSchedule{ScheduleTimeZone: "America/New_York",
+		Holidays: `["2024-11-28",{"date":"2024-11-29","schedule":"09:30-13:00"}]`})
+
+	require.False(t, s.AlwaysOpen)
+	require.True(t, s.IsOpen(time.Date(2024, 11, 27, 23, 0, 0, 0, ny)))
+	require.False(t, s.IsOpen(time.Date(2024, 11, 28, 12, 0, 0, 0, ny)))
+	require.False(t, s.IsOpen(time.Date(2024, 11, 29, 8, 0, 0, 0, ny)))
+	require.True(t, s.IsOpen(time.Date(2024, 11, 29, 10, 0, 0, 0, ny)))
+	require.False(t, s.IsOpen(time.Date(2024, 11, 29, 14, 0, 0, 0, ny)))
+	require.True(t, s.IsOpen(time.Date(2024, 11, 30, 0, 0, 0, 0, ny)))
+
+	require.Equal(t, []Session{
+		{Open: time.Date(2024, 11, 27, 0, 0, 0, 0, ny), Close: time.Date(2024, 11, 28, 0, 0, 0, 0, ny)},
+		{Open: time.Date(2024, 11, 29, 9, 30, 0, 0, ny), Close: time.Date(2024, 11, 29, 13, 0, 0, 0, ny)},
+		{Open: time.Date(2024, 11, 30, 0, 0, 0, 0, ny), Close: time.Date(2024, 12, 1, 0, 0, 0, 0, ny)},
+	}, s.Sessions(time.Date(2024, 11, 27, 0, 0, 0, 0, ny), time.Date(2024, 12, 1, 0, 0, 0, 0, ny)))
+
+	open, err := s.NextOpen(time.Date(2024, 11, 28, 12, 0, 0, 0, ny))
+	require.NoError(t, err)
+	require.True(t, time.Date(2024, 11, 29, 9, 30, 0, 0, ny).Equal(open))
+
+	// A regular non-holiday changes nothing and keeps the asset always open.
+	regular := mustSchedule(t, types.AssetSchedule{NonHolidays: `["2024-11-28"]`})
+	require.True(t, regular.AlwaysOpen)
+}
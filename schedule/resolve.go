@@ -0,0 +1,108 @@
+package schedule
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// Resolve merges the two sources of an asset's schedule field by field: a
+// non-empty value from /assets-schedule wins, otherwise the asset config value
+// is used. The schedule type only exists on /assets-schedule. Resolve returns
+// nil when neither source defines a schedule.
+func Resolve(entry *types.AssetSchedule, config *types.AssetConfig) *types.AssetSchedule {
+	if entry == nil && (config == nil || config.Schedule == "") {
+		return nil
+	}
+
+	resolved := &types.AssetSchedule{}
+	if entry != nil {
+		*resolved = *entry
+	}
+	if config != nil {
+		resolved.ScheduleTimeZone = firstNonEmpty(resolved.ScheduleTimeZone, config.ScheduleTimeZone)
+		resolved.Schedule = firstNonEmpty(resolved.Schedule, config.Schedule)
+		resolved.Holidays = firstNonEmpty(resolved.Holidays, config.Holidays)
+		resolved.NonHolidays = firstNonEmpty(resolved.NonHolidays, config.NonHolidays)
+	}
+	return resolved
+}
+
+// ResolveAll resolves the schedule of every asset found in either source.
+// Assets without a schedule in either source are left out.
+func ResolveAll(entries map[string]*types.AssetSchedule, configs []*types.AssetConfig) map[string]*types.AssetSchedule {
+	byName := make(map[string]*types.AssetConfig)
+	for _, c := range configs {
+		if _, ok := byName[c.Name]; !ok {
+			byName[c.Name] = c
+		}
+	}
+
+	out := make(map[string]*types.AssetSchedule)
+	for name, entry := range entries {
+		if r := Resolve(entry, byName[name]); r != nil {
+			out[name] = r
+		}
+	}
+	for name, c := range byName {
+		if _, ok := out[name]; ok {
+			continue
+		}
+		if r := Resolve(nil, c); r != nil {
+			out[name] = r
+		}
+	}
+	return out
+}
+
+type Mismatch struct {
+	Asset         string `json:"asset"`
+	Field         string `json:"field"`
+	ScheduleValue string `json:"scheduleValue"`
+	ConfigValue   string `json:"configValue"`
+}
+
+// Mismatches lists the fields that both sources set to different values.
+func Mismatches(entries map[string]*types.AssetSchedule, configs []*types.AssetConfig) []Mismatch {
+	var out []Mismatch
+	for _, c := range configs {
+		entry := entries[c.Name]
+		if entry == nil {
+			continue
+		}
+		for _, f := range []struct {
+			name          string
+			entry, config string
+		}{
+			{"scheduleTimeZone", entry.ScheduleTimeZone, c.ScheduleTimeZone},
+			{"schedule", entry.Schedule, c.Schedule},
+			{"holidays", entry.Holidays, c.Holidays},
+			{"nonHolidays", entry.NonHolidays, c.NonHolidays},
+		} {
+			e, cv := normalize(f.entry), normalize(f.config)
+			if e != "" && cv != "" && e != cv {
+				out = append(out, Mismatch{Asset: c.Name, Field: f.name, ScheduleValue: f.entry, ConfigValue: f.config})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Asset < out[j].Asset })
+	return out
+}
+
+func normalize(v string) string {
+	v = strings.Join(strings.Fields(v), "")
+	if v == "[]" {
+		return ""
+	}
+	return v
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestResolvePrecedence(t *testing.T) {
+	entry := &types.AssetSchedule{Schedule: usEquities, Holidays: `["2024-12-25"]`, ScheduleType: types.ScheduleTypeInfo}
+	config := &types.AssetConfig{Name: "SPCX", Schedule: "-|-|-|-|-|-|-", ScheduleTimeZone: "America/New_York", Holidays: "[]"}
+
+	require.Equal(t, &types.AssetSchedule{
+		ScheduleTimeZone: "America/New_York",
+		Schedule:         usEquities,
+		Holidays:         `["2024-12-25"]`,
+		ScheduleType:     types.ScheduleTypeInfo,
+	}, Resolve(entry, config))
+
+	require.Equal(t, &types.AssetSchedule{ScheduleTimeZone: "America/New_York", Schedule: "-|-|-|-|-|-|-", Holidays: "[]"}, Resolve(nil, config))
+	require.Nil(t, Resolve(nil, &types.AssetConfig{Name: "BTC"}))
+	require.Nil(t, Resolve(nil, nil))
+}
+
+func TestResolveAll(t *testing.T) {
+	resolved := ResolveAll(
+		map[string]*types.AssetSchedule{"SPCX": {Schedule: usEquities}, "ETH": nil},
+		[]*types.AssetConfig{
+			{Name: "SPCX", ScheduleTimeZone: "America/New_York"},
+			{Name: "AAPL", Schedule: usEquities},
+			{Name: "BTC"},
+			{Name: "ETH"},
+		},
+	)
+	require.Len(t, resolved, 2)
+	require.NotContains(t, resolved, "ETH")
+	require.Equal(t, "America/New_York", resolved["SPCX"].ScheduleTimeZone)
+	require.Equal(t, usEquities, resolved["AAPL"].Schedule)
+}
+
+func TestMismatches(t *testing.T) {
+	mismatches := Mismatches(
+		map[string]*types.AssetSchedule{
+			"SPCX": {Schedule: usEquities, ScheduleTimeZone: "America/New_York", Holidays: "[]"},
+			"AAPL": {Schedule: usEquities},
+		},
+		[]*types.AssetConfig{
+			{Name: "SPCX", Schedule: "09:30-13:00|-|-|-|-|-|-", ScheduleTimeZone: "America/New_York", Holidays: " [ ] "},
+			{Name: "AAPL", Schedule: usEquities, ScheduleTimeZone: "UTC"},
+			{Name: "BTC", Schedule: usEquities},
+		},
+	)
+	require.Equal(t, []Mismatch{
+		{Asset: "SPCX", Field: "schedule", ScheduleValue: usEquities, ConfigValue: "09:30-13:00|-|-|-|-|-|-"},
+	}, mismatches)
+}
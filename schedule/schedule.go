@@ -0,0 +1,218 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+var (
+	ErrMalformed  = errors.New("malformed schedule")
+	ErrNoSchedule = errors.New("asset has no schedule")
+	// ErrNoTransition is returned when a schedule never opens or closes within
+	// the search horizon, e.g. because it trades around the clock.
+	ErrNoTransition = errors.New("no open or close within horizon")
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+	// horizon bounds searches for the next open or close.
+	horizon = 366 * day
+)
+
+// Window is a trading window relative to local midnight of the day it opens.
+// End can exceed 24h for sessions that run overnight into the next day.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Schedule is a parsed weekly trading schedule in the asset's time zone.
+type Schedule struct {
+	Location    *time.Location
+	Weekly      [7][]Window // indexed by time.Weekday
+	Holidays    map[string]Override
+	NonHolidays map[string]Override
+	AlwaysOpen  bool
+}
+
+// Session is a concrete trading session. Zero Open and Close mean the asset
+// trades without interruption.
+type Session struct {
+	Open  time.Time `json:"open"`
+	Close time.Time `json:"close"`
+}
+
+func (s Session) Contains(t time.Time) bool {
+	if s.Open.IsZero() && s.Close.IsZero() {
+		return true
+	}
+	return !t.Before(s.Open) && t.Before(s.Close)
+}
+
+// Parse parses a schedule such as "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-".
+// Days start on Monday, "-" marks a closed day, several windows of a day are
+// separated by commas and a window whose end is not after its start closes on
+// the next day. An empty schedule trades around the clock.
+func Parse(schedule, timeZone string) (*Schedule, error) {
+	loc, err := LoadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schedule{Location: loc}
+	if strings.TrimSpace(schedule) == "" {
+		s.AlwaysOpen = true
+		return s, nil
+	}
+
+	days := strings.Split(schedule, "|")
+	if len(days) != 7 {
+		return nil, errors.Wrapf(ErrMalformed, "%q: expected 7 days, got %d", schedule, len(days))
+	}
+
+	for i, d := range days {
+		weekday := time.Weekday((i + 1) % 7)
+		windows, err := parseDay(d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", weekday)
+		}
+		s.Weekly[weekday] = windows
+	}
+
+	s.AlwaysOpen = coversWholeWeek(s.Weekly)
+	return s, nil
+}
+
+// LoadLocation loads a schedule time zone, defaulting to UTC when empty.
+func LoadLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load time zone %q", timeZone)
+	}
+	return loc, nil
+}
+
+func parseDay(d string) ([]Window, error) {
+	d = strings.TrimSpace(d)
+	if d == "-" || d == "" {
+		return nil, nil
+	}
+
+	var windows []Window
+	for _, w := range strings.Split(d, ",") {
+		window, err := parseWindow(strings.TrimSpace(w))
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+func parseWindow(w string) (Window, error) {
+	start, end, ok := strings.Cut(w, "-")
+	if !ok {
+		return Window{}, errors.Wrapf(ErrMalformed, "window %q: expected HH:MM-HH:MM", w)
+	}
+
+	s, err := parseClock(start, false)
+	if err != nil {
+		return Window{}, errors.Wrapf(err, "window %q", w)
+	}
+	e, err := parseClock(end, true)
+	if err != nil {
+		return Window{}, errors.Wrapf(err, "window %q", w)
+	}
+	if e <= s {
+		e += day
+	}
+	return Window{Start: s, End: e}, nil
+}
+
+func parseClock(c string, allowEndOfDay bool) (time.Duration, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(c), ":")
+	if !ok || len(h) == 0 || len(h) > 2 || len(m) != 2 {
+		return 0, errors.Wrapf(ErrMalformed, "time %q: expected HH:MM", c)
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, errors.Wrapf(ErrMalformed, "time %q: bad hour", c)
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, errors.Wrapf(ErrMalformed, "time %q: bad minute", c)
+	}
+	if hour < 0 || hour > 24 || (hour == 24 && (minute != 0 || !allowEndOfDay)) {
+		return 0, errors.Wrapf(ErrMalformed, "time %q: bad hour", c)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// coversWholeWeek reports whether the windows leave no gap over a week.
+func coversWholeWeek(weekly [7][]Window) bool {
+	covered := time.Duration(0)
+	for {
+		next := covered
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			// Saturday's overnight windows wrap around into Sunday.
+			for _, offset := range []time.Duration{time.Duration(wd) * day, time.Duration(wd)*day - week} {
+				for _, w := range weekly[wd] {
+					if offset+w.Start <= covered && offset+w.End > next {
+						next = offset + w.End
+					}
+				}
+			}
+		}
+		if next >= week {
+			return true
+		}
+		if next == covered {
+			return false
+		}
+		covered = next
+	}
+}
+
+func FromAssetSchedule(a *types.AssetSchedule) (*Schedule, error) {
+	if a == nil {
+		return nil, ErrNoSchedule
+	}
+	s, err := Parse(a.Schedule, a.ScheduleTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	if s.Holidays, err = ParseOverrides(a.Holidays, false); err != nil {
+		return nil, errors.Wrap(err, "holidays")
+	}
+	if s.NonHolidays, err = ParseOverrides(a.NonHolidays, true); err != nil {
+		return nil, errors.Wrap(err, "non-holidays")
+	}
+	if s.AlwaysOpen && (changesWindows(s.Holidays) || changesWindows(s.NonHolidays)) {
+		if strings.TrimSpace(a.Schedule) == "" {
+			// An empty schedule trades around the clock on regular days.
+			for wd := range s.Weekly {
+				s.Weekly[wd] = []Window{{Start: 0, End: day}}
+			}
+		}
+		s.AlwaysOpen = false
+	}
+	return s, nil
+}
+
+// changesWindows reports whether any override replaces the weekly windows.
+func changesWindows(overrides map[string]Override) bool {
+	for _, o := range overrides {
+		if !o.Regular {
+			return true
+		}
+	}
+	return false
+}
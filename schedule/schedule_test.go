@@ -0,0 +1,146 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+const usEquities = "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-|-"
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestParseWeeklySchedule(t *testing.T) {
+	s, err := Parse(usEquities, "America/New_York")
+	require.NoError(t, err)
+	require.Equal(t, "America/New_York", s.Location.String())
+	require.False(t, s.AlwaysOpen)
+	require.Equal(t, []Window{{Start: 9*time.Hour + 30*time.Minute, End: 16 * time.Hour}}, s.Weekly[time.Monday])
+	require.Empty(t, s.Weekly[time.Saturday])
+	require.Empty(t, s.Weekly[time.Sunday])
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		schedule string
+	}{
+		{"six days", "09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|09:30-16:00|-"},
+		{"missing dash", "0930|-|-|-|-|-|-"},
+		{"bad hour", "25:00-26:00|-|-|-|-|-|-"},
+		{"bad minute", "09:60-16:00|-|-|-|-|-|-"},
+		{"start at 24", "24:00-01:00|-|-|-|-|-|-"},
+		{"not a number", "ab:00-16:00|-|-|-|-|-|-"},
+		{"single digit minute", "09:3-16:00|-|-|-|-|-|-"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.schedule, "UTC")
+			require.True(t, errors.Is(err, ErrMalformed), "%v", err)
+		})
+	}
+
+	_, err := Parse(usEquities, "Mars/Olympus_Mons")
+	require.Error(t, err)
+}
+
+func TestIsOpenInScheduleTimeZone(t *testing.T) {
+	s, err := Parse(usEquities, "America/New_York")
+	require.NoError(t, err)
+	ny := mustLocation(t, "America/New_York")
+
+	for _, tc := range []struct {
+		at   time.Time
+		open bool
+	}{
+		{time.Date(2024, 1, 8, 9, 29, 59, 0, ny), false},
+		{time.Date(2024, 1, 8, 9, 30, 0, 0, ny), true},
+		{time.Date(2024, 1, 8, 15, 59, 0, 0, ny), true},
+		{time.Date(2024, 1, 8, 16, 0, 0, 0, ny), false},
+		{time.Date(2024, 1, 8, 14, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 6, 12, 0, 0, 0, ny), false},
+		{time.Date(2024, 7, 8, 13, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 7, 8, 13, 29, 0, 0, time.UTC), false},
+	} {
+		require.Equal(t, tc.open, s.IsOpen(tc.at), tc.at.String())
+	}
+
+	session, ok := s.CurrentSession(time.Date(2024, 1, 8, 12, 0, 0, 0, ny))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 8, 9, 30, 0, 0, ny), session.Open)
+	require.Equal(t, time.Date(2024, 1, 8, 16, 0, 0, 0, ny), session.Close)
+}
+
+func TestOvernightSessions(t *testing.T) {
+	// Futures-like week: Sunday 18:00 to Friday 17:00 with a daily break.
+	s, err := Parse("18:00-17:00|18:00-17:00|18:00-17:00|18:00-17:00|-|-|18:00-17:00", "America/Chicago")
+	require.NoError(t, err)
+	chicago := mustLocation(t, "America/Chicago")
+
+	require.True(t, s.IsOpen(time.Date(2024, 1, 7, 23, 0, 0, 0, chicago)))
+	require.True(t, s.IsOpen(time.Date(2024, 1, 8, 3, 0, 0, 0, chicago)))
+	require.False(t, s.IsOpen(time.Date(2024, 1, 8, 17, 30, 0, 0, chicago)))
+	require.True(t, s.IsOpen(time.Date(2024, 1, 12, 16, 59, 0, 0, chicago)))
+	require.False(t, s.IsOpen(time.Date(2024, 1, 12, 17, 0, 0, 0, chicago)))
+
+	session, ok := s.CurrentSession(time.Date(2024, 1, 9, 2, 0, 0, 0, chicago))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 8, 18, 0, 0, 0, chicago), session.Open)
+	require.Equal(t, time.Date(2024, 1, 9, 17, 0, 0, 0, chicago), session.Close)
+}
+
+func TestBackToBackWindowsAreMerged(t *testing.T) {
+	s, err := Parse("00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-22:00|-|22:00-24:00", "UTC")
+	require.NoError(t, err)
+	require.False(t, s.AlwaysOpen)
+
+	session, ok := s.CurrentSession(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 7, 22, 0, 0, 0, time.UTC), session.Open)
+	require.Equal(t, time.Date(2024, 1, 12, 22, 0, 0, 0, time.UTC), session.Close)
+}
+
+func TestAlwaysOpen(t *testing.T) {
+	for _, schedule := range []string{
+		"",
+		"00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00|00:00-24:00",
+		"00:00-00:00|00:00-00:00|00:00-00:00|00:00-00:00|00:00-00:00|00:00-00:00|00:00-00:00",
+		"12:00-12:00|12:00-12:00|12:00-12:00|12:00-12:00|12:00-12:00|12:00-12:00|12:00-12:00",
+	} {
+		s, err := Parse(schedule, "")
+		require.NoError(t, err)
+		require.True(t, s.AlwaysOpen, schedule)
+
+		session, ok := s.CurrentSession(time.Now())
+		require.True(t, ok)
+		require.True(t, session.Open.IsZero())
+	}
+}
+
+func TestFromAssetSchedule(t *testing.T) {
+	_, err := FromAssetSchedule(nil)
+	require.True(t, errors.Is(err, ErrNoSchedule))
+
+	s, err := FromAssetSchedule(&types.AssetSchedule{Schedule: usEquities, ScheduleTimeZone: "America/New_York"})
+	require.NoError(t, err)
+	require.Equal(t, "America/New_York", s.Location.String())
+}
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := LoadLocation("")
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, loc)
+
+	loc, err = LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	require.Equal(t, "Asia/Tokyo", loc.String())
+
+	_, err = LoadLocation("America/Gotham")
+	require.Error(t, err)
+}
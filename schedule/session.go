@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+)
+
+// IsOpen reports whether t falls into a trading session.
+func (s *Schedule) IsOpen(t time.Time) bool {
+	_, ok := s.CurrentSession(t)
+	return ok
+}
+
+// CurrentSession returns the session containing t. Back-to-back windows, such
+// as a session running past midnight into the next day's window, are merged
+// into a single session.
+func (s *Schedule) CurrentSession(t time.Time) (Session, bool) {
+	if s.AlwaysOpen {
+		return Session{}, true
+	}
+	for _, session := range s.sessions(t, t) {
+		if session.Contains(t) {
+			return session, true
+		}
+	}
+	return Session{}, false
+}
+
+// Sessions returns the sessions overlapping [from, to), clipped to the range.
+func (s *Schedule) Sessions(from, to time.Time) []Session {
+	if !from.Before(to) {
+		return nil
+	}
+	if s.AlwaysOpen {
+		return []Session{{Open: from, Close: to}}
+	}
+
+	var out []Session
+	for _, session := range s.sessions(from, to) {
+		if !session.Close.After(from) || !session.Open.Before(to) {
+			continue
+		}
+		if session.Open.Before(from) {
+			session.Open = from
+		}
+		if session.Close.After(to) {
+			session.Close = to
+		}
+		out = append(out, session)
+	}
+	return out
+}
+
+// sessions returns merged sessions around [from, to]. The scanned dates grow
+// until the sessions at both ends are bounded by a real gap, so that no session
+// overlapping [from, to] is cut at an arbitrary scan boundary. A session that
+// still runs past horizon on either side is cut there.
+func (s *Schedule) sessions(from, to time.Time) []Session {
+	from, to = from.In(s.Location), to.In(s.Location)
+	// A window closes less than two days after the date it opens on.
+	first := localDate(from).AddDate(0, 0, -2)
+	last := localDate(to)
+	minFirst, maxLast := localDate(from.Add(-horizon)), localDate(to.Add(horizon))
+
+	raw := s.sessionsBetween(first, last)
+	for step := 7; ; step *= 2 {
+		merged := merge(raw)
+		if len(merged) == 0 {
+			return nil
+		}
+
+		grown := false
+		if head := merged[0]; first.After(minFirst) && !head.Open.After(first.AddDate(0, 0, 2)) && !head.Close.Before(from) {
+			next := first.AddDate(0, 0, -step)
+			if next.Before(minFirst) {
+				next = minFirst
+			}
+			raw = append(raw, s.sessionsBetween(next, first.AddDate(0, 0, -1))...)
+			first, grown = next, true
+		}
+		if tail := merged[len(merged)-1]; last.Before(maxLast) && !tail.Close.Before(last.AddDate(0, 0, 1)) && !tail.Open.After(to) {
+			next := last.AddDate(0, 0, step)
+			if next.After(maxLast) {
+				next = maxLast
+			}
+			raw = append(raw, s.sessionsBetween(last.AddDate(0, 0, 1), next)...)
+			last, grown = next, true
+		}
+		if !grown {
+			return merged
+		}
+	}
+}
+
+// sessionsBetween returns the sessions opening on the local dates from first
+// to last.
+func (s *Schedule) sessionsBetween(first, last time.Time) []Session {
+	var out []Session
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		out = append(out, s.sessionsOn(d)...)
+	}
+	return out
+}
+
+func localDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// sessionsOn returns the sessions opening on the local date of d.
+func (s *Schedule) sessionsOn(d time.Time) []Session {
+	var out []Session
+	for _, w := range s.windowsOn(d) {
+		out = append(out, Session{Open: at(d, w.Start), Close: at(d, w.End)})
+	}
+	return out
+}
+
+// at returns the wall clock time offset after local midnight of d. Building the
+// time from calendar fields keeps sessions on the same wall clock across DST.
+func at(d time.Time, offset time.Duration) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, d.Location())
+}
+
+func merge(sessions []Session) []Session {
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Open.Before(sessions[j].Open) })
+
+	var out []Session
+	for _, session := range sessions {
+		if !session.Open.Before(session.Close) {
+			continue
+		}
+		if n := len(out); n > 0 && !session.Open.After(out[n-1].Close) {
+			if session.Close.After(out[n-1].Close) {
+				out[n-1].Close = session.Close
+			}
+			continue
+		}
+		out = append(out, session)
+	}
+	return out
+}
+
+// NextOpen returns the first session open strictly after t.
+func (s *Schedule) NextOpen(t time.Time) (time.Time, error) {
+	if s.AlwaysOpen {
+		return time.Time{}, ErrNoTransition
+	}
+	for from := t; from.Before(t.Add(horizon)); from = from.Add(week) {
+		for _, session := range s.sessions(from, from.Add(week)) {
+			if session.Open.After(t) {
+				return session.Open, nil
+			}
+			if session.Close.After(t.Add(horizon)) {
+				return time.Time{}, ErrNoTransition
+			}
+		}
+	}
+	return time.Time{}, ErrNoTransition
+}
+
+// NextClose returns the close of the session containing t, or of the next
+// session when the market is closed at t.
+func (s *Schedule) NextClose(t time.Time) (time.Time, error) {
+	if s.AlwaysOpen {
+		return time.Time{}, ErrNoTransition
+	}
+	for from := t; from.Before(t.Add(horizon)); from = from.Add(week) {
+		for _, session := range s.sessions(from, from.Add(week)) {
+			if session.Close.After(t.Add(horizon)) {
+				return time.Time{}, ErrNoTransition
+			}
+			if session.Close.After(t) {
+				return session.Close, nil
+			}
+		}
+	}
+	return time.Time{}, ErrNoTransition
+}
+
+// TimeUntilClose returns how long the session containing t keeps trading, or
+// zero when the market is closed at t.
+func (s *Schedule) TimeUntilClose(t time.Time) (time.Duration, error) {
+	if s.AlwaysOpen {
+		return 0, ErrNoTransition
+	}
+	session, ok := s.CurrentSession(t)
+	if !ok {
+		return 0, nil
+	}
+	if session.Close.After(t.Add(horizon)) {
+		return 0, ErrNoTransition
+	}
+	return session.Close.Sub(t), nil
+}
@@ -0,0 +1,5 @@
+package schedule
+
+// Containers without zoneinfo, e.g. distroless images, fall back to the time
+// zone database embedded in the binary.
+import _ "time/tzdata"
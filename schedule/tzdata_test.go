@@ -0,0 +1,35 @@
+package schedule
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/test-go/testify/require"
+)
+
+// TestEmbeddedTimeZoneData checks that the package links the time zone
+// database. The fallback can't be observed at run time on a host that has
+// zoneinfo, since the time package always consults the system first.
+func TestEmbeddedTimeZoneData(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	var importers []string
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.ImportsOnly)
+		require.NoError(t, err)
+		for _, imp := range f.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == "time/tzdata" {
+				importers = append(importers, name)
+			}
+		}
+	}
+	require.NotEmpty(t, importers, "no file of the package imports time/tzdata")
+}
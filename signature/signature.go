@@ -0,0 +1,133 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Header carries a base64 encoded detached signature over the response body.
+const Header = "X-Signature"
+
+// CompanionSuffix is appended to a resource path to fetch its detached signature
+// when the response has no signature header.
+const CompanionSuffix = ".sig"
+
+var (
+	ErrUnsigned      = errors.New("config is not signed")
+	ErrBadSignature  = errors.New("config signature is invalid")
+	ErrNoTrustedKeys = errors.New("no trusted keys configured")
+)
+
+// Error is returned for every resource refused by a Verifier.
+type Error struct {
+	Resource string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return "verify " + e.Resource + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Verifier checks detached ed25519 signatures against a set of trusted keys.
+// Keys can be replaced at any time with SetKeys to support rotation.
+type Verifier struct {
+	mu   sync.RWMutex
+	keys []ed25519.PublicKey
+}
+
+func NewVerifier(keys ...ed25519.PublicKey) *Verifier {
+	v := &Verifier{}
+	v.SetKeys(keys...)
+	return v
+}
+
+func (v *Verifier) SetKeys(keys ...ed25519.PublicKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = append([]ed25519.PublicKey(nil), keys...)
+}
+
+func (v *Verifier) Keys() []ed25519.PublicKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return append([]ed25519.PublicKey(nil), v.keys...)
+}
+
+// Verify checks an encoded signature over body. Any trusted key may match.
+func (v *Verifier) Verify(resource string, body []byte, encodedSig string) error {
+	keys := v.Keys()
+	if len(keys) == 0 {
+		return &Error{Resource: resource, Err: ErrNoTrustedKeys}
+	}
+
+	encodedSig = strings.TrimSpace(encodedSig)
+	if encodedSig == "" {
+		return &Error{Resource: resource, Err: ErrUnsigned}
+	}
+
+	sig, err := DecodeSignature(encodedSig)
+	if err != nil {
+		return &Error{Resource: resource, Err: ErrBadSignature}
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, body, sig) {
+			return nil
+		}
+	}
+
+	return &Error{Resource: resource, Err: ErrBadSignature}
+}
+
+// CompanionURI returns the location of the detached signature of uri,
+// keeping any query string after the suffixed path.
+func CompanionURI(uri string) string {
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		return uri[:i] + CompanionSuffix + uri[i:]
+	}
+	return uri + CompanionSuffix
+}
+
+func DecodeSignature(s string) ([]byte, error) {
+	sig, err := decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, errors.Errorf("signature must be %d bytes, got %d", ed25519.SignatureSize, len(sig))
+	}
+	return sig, nil
+}
+
+// ParsePublicKey accepts a hex or base64 encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := decode(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode public key")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return key, nil
+}
+
+func decode(s string) ([]byte, error) {
+	if b, err := hex.DecodeString(s); err == nil {
+		return b, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("value is neither hex nor base64")
+}
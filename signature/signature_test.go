@@ -0,0 +1,73 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/test-go/testify/require"
+)
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return pub, priv
+}
+
+func TestVerifierAcceptsAnyTrustedKey(t *testing.T) {
+	oldPub, oldPriv := newKey(t)
+	newPub, newPriv := newKey(t)
+	body := []byte(`{"composedAt":"1"}`)
+
+	v := NewVerifier(oldPub, newPub)
+	require.NoError(t, v.Verify("config", body, base64.StdEncoding.EncodeToString(ed25519.Sign(oldPriv, body))))
+	require.NoError(t, v.Verify("config", body, hex.EncodeToString(ed25519.Sign(newPriv, body))))
+
+	v.SetKeys(newPub)
+	err := v.Verify("config", body, base64.StdEncoding.EncodeToString(ed25519.Sign(oldPriv, body)))
+	require.True(t, errors.Is(err, ErrBadSignature))
+}
+
+func TestVerifierRejections(t *testing.T) {
+	pub, priv := newKey(t)
+	body := []byte(`{"composedAt":"1"}`)
+	v := NewVerifier(pub)
+
+	var sigErr *Error
+	err := v.Verify("assets", body, "")
+	require.True(t, errors.As(err, &sigErr))
+	require.Equal(t, "assets", sigErr.Resource)
+	require.True(t, errors.Is(err, ErrUnsigned))
+
+	err = v.Verify("assets", body, "not a signature")
+	require.True(t, errors.Is(err, ErrBadSignature))
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, body))
+	err = v.Verify("assets", []byte(`{"composedAt":"2"}`), sig)
+	require.True(t, errors.Is(err, ErrBadSignature))
+
+	err = NewVerifier().Verify("assets", body, sig)
+	require.True(t, errors.Is(err, ErrNoTrustedKeys))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _ := newKey(t)
+
+	key, err := ParsePublicKey(hex.EncodeToString(pub))
+	require.NoError(t, err)
+	require.Equal(t, pub, key)
+
+	key, err = ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	require.NoError(t, err)
+	require.Equal(t, pub, key)
+
+	_, err = ParsePublicKey("abcd")
+	require.Error(t, err)
+}
+
+func TestCompanionURI(t *testing.T) {
+	require.Equal(t, "http://host/config/assets.sig", CompanionURI("http://host/config/assets"))
+	require.Equal(t, "http://host/config/vpi-history.sig?since=1", CompanionURI("http://host/config/vpi-history?since=1"))
+}
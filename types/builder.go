@@ -0,0 +1,63 @@
+package types
+
+import "github.com/pkg/errors"
+
+// RebateDecimal returns the rebate rate, zero when unset.
+func (b Builder) RebateDecimal() (Decimal, error) {
+	return parseRate(b.Rebate)
+}
+
+// DiscountDecimal returns the discount rate, zero when unset.
+func (b Builder) DiscountDecimal() (Decimal, error) {
+	return parseRate(b.Discount)
+}
+
+func parseRate(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, nil
+	}
+	return ParseDecimal(s)
+}
+
+// BuilderFee is a base fee split by Builder.ApplyFee. Every amount has the
+// scale of the base fee plus that of the rates, so nothing is rounded.
+type BuilderFee struct {
+	Base Decimal
+	// Discount is the part of Base the trader doesn't pay.
+	Discount Decimal
+	// Fee is what the trader pays, Base minus Discount.
+	Fee Decimal
+	// Rebate is the part of Fee paid out to the builder.
+	Rebate Decimal
+	// Protocol is the part of Fee kept by the protocol, Fee minus Rebate.
+	Protocol Decimal
+}
+
+var one = NewDecimalFromInt(1)
+
+// ApplyFee applies the builder's discount to base and its rebate to the
+// discounted fee. Both rates must lie within [0, 1].
+func (b Builder) ApplyFee(base Decimal) (BuilderFee, error) {
+	discount, err := b.DiscountDecimal()
+	if err != nil {
+		return BuilderFee{}, errors.Wrapf(err, "builder %s discount", b.Builder)
+	}
+	rebate, err := b.RebateDecimal()
+	if err != nil {
+		return BuilderFee{}, errors.Wrapf(err, "builder %s rebate", b.Builder)
+	}
+	for _, r := range []struct {
+		name string
+		rate Decimal
+	}{{"discount", discount}, {"rebate", rebate}} {
+		if r.rate.Sign() < 0 || r.rate.Cmp(one) > 0 {
+			return BuilderFee{}, errors.Errorf("builder %s %s %s is outside [0, 1]", b.Builder, r.name, r.rate)
+		}
+	}
+
+	f := BuilderFee{Base: base, Discount: base.Mul(discount)}
+	f.Fee = base.Sub(f.Discount)
+	f.Rebate = f.Fee.Mul(rebate)
+	f.Protocol = f.Fee.Sub(f.Rebate)
+	return f, nil
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/require"
+)
+
+func TestBuilderApplyFee(t *testing.T) {
+	f, err := Builder{Builder: "b", Rebate: "0.3", Discount: "0.1"}.ApplyFee(MustParseDecimal("1.000000001"))
+	require.NoError(t, err)
+	require.Equal(t, "0.1000000001", f.Discount.String())
+	require.Equal(t, "0.9000000009", f.Fee.String())
+	require.Equal(t, "0.27000000027", f.Rebate.String())
+	require.Equal(t, "0.63000000063", f.Protocol.String())
+	require.True(t, f.Discount.Add(f.Rebate).Add(f.Protocol).Equal(f.Base))
+
+	f, err = Builder{Builder: "b"}.ApplyFee(MustParseDecimal("5"))
+	require.NoError(t, err)
+	require.Equal(t, "5", f.Fee.String())
+	require.True(t, f.Rebate.IsZero())
+
+	_, err = Builder{Builder: "b", Rebate: "1.5"}.ApplyFee(MustParseDecimal("5"))
+	require.EqualError(t, err, "builder b rebate 1.5 is outside [0, 1]")
+	_, err = Builder{Builder: "b", Discount: "-0.1"}.ApplyFee(MustParseDecimal("5"))
+	require.Error(t, err)
+	_, err = Builder{Builder: "b", Discount: "ten"}.ApplyFee(MustParseDecimal("5"))
+	require.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Amount converts an on-chain integer amount into asset units, e.g. 1500000
+// into 1.500000 for 6 decimals.
+func (a CollateralAsset) Amount(onchain *big.Int) Decimal {
+	return NewDecimal(onchain, int32(a.Decimals))
+}
+
+// OnChain converts an amount in asset units into the on-chain integer and
+// fails when it has more decimals than the asset.
+func (a CollateralAsset) OnChain(amount Decimal) (*big.Int, error) {
+	if !amount.Round(int32(a.Decimals), RoundDown).Equal(amount) {
+		return nil, errors.Errorf("%s has more than %d decimals of %s", amount, a.Decimals, a.Name)
+	}
+	return a.RoundOnChain(amount, RoundDown), nil
+}
+
+// RoundOnChain is OnChain rounding the decimals the asset can't represent.
+func (a CollateralAsset) RoundOnChain(amount Decimal, mode RoundingMode) *big.Int {
+	return amount.Round(int32(a.Decimals), mode).Unscaled()
+}
+
+// ParseAmount parses a human amount such as "1.5" into the on-chain integer.
+func (a CollateralAsset) ParseAmount(s string) (*big.Int, error) {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return nil, err
+	}
+	return a.OnChain(d)
+}
+
+// FormatAmount formats an on-chain integer amount in asset units.
+func (a CollateralAsset) FormatAmount(onchain *big.Int, f DecimalFormat) string {
+	return a.Amount(onchain).Format(f)
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/test-go/testify/require"
+)
+
+func TestCollateralAmounts(t *testing.T) {
+	usdt := CollateralAsset{Name: "USDT", Decimals: 6}
+	huge, _ := new(big.Int).SetString("123456789012345678901234567", 10)
+
+	require.Equal(t, "1.500000", usdt.Amount(big.NewInt(1_500_000)).String())
+	require.Equal(t, "123456789012345678901.234567", usdt.Amount(huge).String())
+	require.Equal(t, "123,456,789,012,345,678,901.23", usdt.FormatAmount(huge, DecimalFormat{Round: true, Scale: 2, ThousandsSeparator: ","}))
+	require.Equal(t, "0.000001", usdt.FormatAmount(big.NewInt(1), DecimalFormat{TrimZeros: true}))
+
+	for in, want := range map[string]string{
+		"1.5":                          "1500000",
+		"0.000001":                     "1",
+		"-2":                           "-2000000",
+		"1.500000000":                  "1500000",
+		"123456789012345678901.234567": huge.String(),
+	} {
+		n, err := usdt.ParseAmount(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, n.String(), in)
+		require.Equal(t, 0, usdt.Amount(n).Cmp(MustParseDecimal(in)), in)
+	}
+
+	_, err := usdt.ParseAmount("0.0000001")
+	require.EqualError(t, err, "0.0000001 has more than 6 decimals of USDT")
+	_, err = usdt.ParseAmount("1,5")
+	require.Error(t, err)
+
+	require.Equal(t, "1", usdt.RoundOnChain(MustParseDecimal("0.0000005"), RoundHalfUp).String())
+	require.Equal(t, "0", usdt.RoundOnChain(MustParseDecimal("0.0000005"), RoundHalfEven).String())
+	require.Equal(t, "0", usdt.RoundOnChain(MustParseDecimal("0.0000009"), RoundDown).String())
+}
@@ -0,0 +1,346 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrMalformedDecimal = errors.New("malformed decimal")
+
+// Decimal is an exact fixed-point number worth Unscaled * 10^-Scale. The zero
+// value is 0.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+// maxDecimalScale bounds parsed exponents so that hostile input can't make
+// ParseDecimal allocate huge powers of ten.
+const maxDecimalScale = 1000
+
+var bigTen = big.NewInt(10)
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// NewDecimal returns unscaled * 10^-scale. A negative scale multiplies unscaled.
+func NewDecimal(unscaled *big.Int, scale int32) Decimal {
+	v := new(big.Int)
+	if unscaled != nil {
+		v.Set(unscaled)
+	}
+	if scale < 0 {
+		return Decimal{value: v.Mul(v, pow10(-scale))}
+	}
+	return Decimal{value: v, scale: scale}
+}
+
+func NewDecimalFromInt(n int64) Decimal {
+	return Decimal{value: big.NewInt(n)}
+}
+
+// ParseDecimal parses values such as "12", "-0.015" or "1.5e-3". Fractions,
+// hex and special values are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent, hasExponent := s, "", false
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exponent, hasExponent = s[:i], s[i+1:], true
+	}
+
+	digits := strings.TrimLeft(mantissa, "+-")
+	if len(mantissa)-len(digits) > 1 {
+		return Decimal{}, errors.Wrapf(ErrMalformedDecimal, "%q", s)
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart+fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, errors.Wrapf(ErrMalformedDecimal, "%q", s)
+	}
+
+	scale := int64(len(fracPart))
+	if hasExponent {
+		exp, err := strconv.ParseInt(exponent, 10, 32)
+		if err != nil {
+			return Decimal{}, errors.Wrapf(ErrMalformedDecimal, "%q: bad exponent", s)
+		}
+		scale -= exp
+	}
+	if scale > maxDecimalScale || scale < -maxDecimalScale {
+		return Decimal{}, errors.Wrapf(ErrMalformedDecimal, "%q: exponent out of range", s)
+	}
+
+	v, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if strings.HasPrefix(mantissa, "-") {
+		v.Neg(v)
+	}
+	return NewDecimal(v, int32(scale)), nil
+}
+
+// MustParseDecimal is ParseDecimal for constants, panicking on malformed input.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// Unscaled returns a copy of the integer d is built from.
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.int())
+}
+
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// rescale returns the unscaled value of d at a scale of at least d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := new(big.Int).Set(d.int())
+	if scale > d.scale {
+		v.Mul(v, pow10(scale-d.scale))
+	}
+	return v
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return Decimal{value: new(big.Int).Add(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return Decimal{value: new(big.Int).Sub(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o with scale decimals, truncated toward zero.
+func (d Decimal) Div(o Decimal, scale int32) (Decimal, error) {
+	if o.Sign() == 0 {
+		return Decimal{}, errors.New("decimal division by zero")
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	num := new(big.Int).Mul(d.int(), pow10(o.scale+scale))
+	den := new(big.Int).Mul(o.int(), pow10(d.scale))
+	return Decimal{value: num.Quo(num, den), scale: scale}, nil
+}
+
+// Truncate drops the decimals beyond scale, rounding toward zero.
+func (d Decimal) Truncate(scale int32) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= d.scale {
+		return Decimal{value: d.Unscaled(), scale: d.scale}
+	}
+	return Decimal{value: new(big.Int).Quo(d.int(), pow10(d.scale-scale)), scale: scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares the values regardless of scale, so 1.50 equals 1.5.
+func (d Decimal) Cmp(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.rescale(scale).Cmp(o.rescale(scale))
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Unscaled(), pow10(d.scale))
+}
+
+// Float64 is the nearest float64, for display and approximate math only.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// String formats d with exactly Scale decimals and no exponent.
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON writes d as a string so that no precision is lost.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number. Null and "" leave d zero.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = Decimal{}
+			return nil
+		}
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds half away from zero, the usual display rounding.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds half to the even neighbour.
+	RoundHalfEven
+	// RoundDown rounds toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+// Round returns d with exactly scale decimals, rounding with mode when
+// decimals are dropped and padding with zeros otherwise.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= d.scale {
+		return Decimal{value: d.rescale(scale), scale: scale}
+	}
+
+	divisor := pow10(d.scale - scale)
+	q, r := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+	if r.Sign() == 0 {
+		return Decimal{value: q, scale: scale}
+	}
+
+	sign := d.Sign()
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	var away bool
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = sign < 0
+	case RoundCeiling:
+		away = sign > 0
+	case RoundHalfEven:
+		c := half.Cmp(divisor)
+		away = c > 0 || (c == 0 && q.Bit(0) == 1)
+	default:
+		away = half.Cmp(divisor) >= 0
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return Decimal{value: q, scale: scale}
+}
+
+// DecimalFormat controls Decimal.Format. The zero value matches String.
+type DecimalFormat struct {
+	// Round to Scale decimals with Rounding. Otherwise every decimal is kept.
+	Round    bool
+	Scale    int32
+	Rounding RoundingMode
+	// TrimZeros drops trailing fractional zeros, and the point when none are left.
+	TrimZeros bool
+	// ThousandsSeparator, e.g. ",", groups the integer digits by three.
+	ThousandsSeparator string
+}
+
+func (d Decimal) Format(f DecimalFormat) string {
+	if f.Round {
+		d = d.Round(f.Scale, f.Rounding)
+	}
+	s := d.String()
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if f.TrimZeros {
+		fracPart = strings.TrimRight(fracPart, "0")
+	}
+	if f.ThousandsSeparator != "" && len(intPart) > 3 {
+		var b strings.Builder
+		lead := len(intPart) % 3
+		if lead == 0 {
+			lead = 3
+		}
+		b.WriteString(intPart[:lead])
+		for i := lead; i < len(intPart); i += 3 {
+			b.WriteString(f.ThousandsSeparator)
+			b.WriteString(intPart[i : i+3])
+		}
+		intPart = b.String()
+	}
+	if fracPart != "" {
+		intPart += "." + fracPart
+	}
+	return sign + intPart
+}
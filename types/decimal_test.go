@@ -0,0 +1,147 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/test-go/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for in, want := range map[string]string{
+		"12":       "12",
+		"-0.015":   "-0.015",
+		"+1.50":    "1.50",
+		".5":       "0.5",
+		"1.":       "1",
+		"1.5e-3":   "0.0015",
+		"25E2":     "2500",
+		"0.000001": "0.000001",
+		"123456789012345678901234567890.123456789": "123456789012345678901234567890.123456789",
+	} {
+		d, err := ParseDecimal(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, d.String(), in)
+	}
+
+	for _, in := range []string{"", "-", ".", "1/3", "0x10", "1e", "1e+", "--1", "1.2.3", " 1", "NaN", "Inf", "1e5000"} {
+		_, err := ParseDecimal(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("1.25")
+	b := MustParseDecimal("-0.5")
+
+	require.Equal(t, "0.75", a.Add(b).String())
+	require.Equal(t, "1.75", a.Sub(b).String())
+	require.Equal(t, "-0.625", a.Mul(b).String())
+	q, err := a.Div(MustParseDecimal("3"), 4)
+	require.NoError(t, err)
+	require.Equal(t, "0.4166", q.String())
+	q, err = b.Div(MustParseDecimal("0.3"), 2)
+	require.NoError(t, err)
+	require.Equal(t, "-1.66", q.String())
+	_, err = a.Div(Decimal{}, 2)
+	require.Error(t, err)
+
+	require.Equal(t, "-1.2", MustParseDecimal("-1.29").Truncate(1).String())
+	require.Equal(t, "1.25", a.Truncate(5).String())
+	require.Equal(t, "0.5", b.Abs().String())
+	require.Equal(t, "-1.25", a.Neg().String())
+	require.Equal(t, 1.25, a.Float64())
+	require.Equal(t, "0", Decimal{}.String())
+	require.True(t, Decimal{}.IsZero())
+	require.Equal(t, "0.05", NewDecimal(nil, 2).Add(NewDecimalFromInt(5).Mul(NewDecimal(nil, 0).Add(MustParseDecimal("0.01")))).String())
+	require.Equal(t, "1500", NewDecimal(NewDecimalFromInt(15).Unscaled(), -2).String())
+}
+
+func TestDecimalCmp(t *testing.T) {
+	require.Equal(t, 0, MustParseDecimal("1.50").Cmp(MustParseDecimal("1.5")))
+	require.True(t, MustParseDecimal("1.5").Equal(MustParseDecimal("15e-1")))
+	require.Equal(t, -1, MustParseDecimal("-2").Cmp(MustParseDecimal("0.001")))
+	require.Equal(t, 1, MustParseDecimal("0.1").Cmp(Decimal{}))
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		String Decimal `json:"string"`
+		Number Decimal `json:"number"`
+		Empty  Decimal `json:"empty"`
+		Null   Decimal `json:"null"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"string":"0.05","number":12.500,"empty":"","null":null}`), &v))
+	require.Equal(t, "0.05", v.String.String())
+	require.Equal(t, "12.500", v.Number.String())
+	require.True(t, v.Empty.IsZero())
+	require.True(t, v.Null.IsZero())
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `{"string":"0.05","number":"12.500","empty":"0","null":"0"}`, string(out))
+
+	require.Error(t, json.Unmarshal([]byte(`{"string":"ten"}`), &v))
+	require.Error(t, json.Unmarshal([]byte(`{"string":true}`), &v))
+}
+
+func TestTypedAccessors(t *testing.T) {
+	b := Builder{Rebate: "0.1", Discount: "5e-2"}
+	rebate, err := b.RebateDecimal()
+	require.NoError(t, err)
+	require.Equal(t, "0.1", rebate.String())
+	discount, err := b.DiscountDecimal()
+	require.NoError(t, err)
+	require.Equal(t, "0.05", discount.String())
+
+	_, err = Builder{Rebate: "ten"}.RebateDecimal()
+	require.Error(t, err)
+
+	d, err := VPIParams{MarketDepthLong: "1000000000000000000000", MarketDepthShort: "2", Spread: "500000", K: "1"}.Decimals()
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000000", d.MarketDepthLong.String())
+	require.Equal(t, "500000", d.Spread.String())
+
+	_, err = VPIParams{MarketDepthLong: "1", MarketDepthShort: "2", Spread: "1.5", K: "1"}.Decimals()
+	require.EqualError(t, err, `vpi spread "1.5" is not an integer`)
+}
+
+func TestDecimalRound(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		mode RoundingMode
+		want string
+	}{
+		{"1.25", RoundHalfUp, "1.3"},
+		{"-1.25", RoundHalfUp, "-1.3"},
+		{"1.24", RoundHalfUp, "1.2"},
+		{"1.25", RoundHalfEven, "1.2"},
+		{"1.35", RoundHalfEven, "1.4"},
+		{"1.251", RoundHalfEven, "1.3"},
+		{"1.29", RoundDown, "1.2"},
+		{"-1.29", RoundDown, "-1.2"},
+		{"1.21", RoundUp, "1.3"},
+		{"-1.21", RoundUp, "-1.3"},
+		{"-1.21", RoundFloor, "-1.3"},
+		{"1.29", RoundFloor, "1.2"},
+		{"1.21", RoundCeiling, "1.3"},
+		{"-1.29", RoundCeiling, "-1.2"},
+		{"-0.04", RoundHalfUp, "0.0"},
+		{"1.2", RoundUp, "1.2"},
+		{"7", RoundDown, "7.0"},
+	} {
+		require.Equal(t, tc.want, MustParseDecimal(tc.in).Round(1, tc.mode).String(), "%s mode %d", tc.in, tc.mode)
+	}
+}
+
+func TestDecimalFormat(t *testing.T) {
+	d := MustParseDecimal("-1234567.891000")
+	require.Equal(t, d.String(), d.Format(DecimalFormat{}))
+	require.Equal(t, "-1,234,567.891000", d.Format(DecimalFormat{ThousandsSeparator: ","}))
+	require.Equal(t, "-1 234 567.891", d.Format(DecimalFormat{ThousandsSeparator: " ", TrimZeros: true}))
+	require.Equal(t, "-1'234'568", d.Format(DecimalFormat{Round: true, Rounding: RoundHalfUp, ThousandsSeparator: "'"}))
+	require.Equal(t, "-1234567.89", d.Format(DecimalFormat{Round: true, Scale: 2, Rounding: RoundDown}))
+	require.Equal(t, "100", MustParseDecimal("100.000").Format(DecimalFormat{TrimZeros: true}))
+	require.Equal(t, "123", MustParseDecimal("123").Format(DecimalFormat{ThousandsSeparator: ","}))
+	require.Equal(t, "123,456", MustParseDecimal("123456").Format(DecimalFormat{ThousandsSeparator: ","}))
+}
@@ -0,0 +1,513 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrExtraNotFound = errors.New("extra field not found")
+
+// Extras is embedded in every type decoded from the config service. It keeps
+// the object fields the type has no struct field for and, when the service
+// encodes a value differently than this module would (key order, numbers sent
+// as strings or the reverse, explicit zeros), the form of each field as
+// received, so that an unmodified value re-encodes to the same bytes, compacted.
+// Only the fields of the type itself are kept: nested values keep their own,
+// and maps are encoded with sorted keys.
+//
+// Extras only holds strings, so the types stay comparable: values decoded from
+// the same input are ==, and values decoded from canonical JSON without unknown
+// fields are == to literals.
+type Extras struct {
+	// form is an object of every received key in order, with the value as
+	// received for scalar fields and {} for the others.
+	form string
+	// fields is an object of the unknown fields in received order.
+	fields string
+}
+
+// Extra returns the raw value of a field the service sent that the type has no
+// struct field for.
+func (e Extras) Extra(key string) (json.RawMessage, bool) {
+	var out json.RawMessage
+	members([]byte(e.fields), func(k, v []byte) {
+		if out == nil && unquoteKey(k) == key {
+			out = json.RawMessage(v)
+		}
+	})
+	return out, out != nil
+}
+
+// ExtraKeys returns the names of the unknown fields in sorted order.
+func (e Extras) ExtraKeys() []string {
+	keys := []string{}
+	members([]byte(e.fields), func(k, _ []byte) {
+		keys = append(keys, unquoteKey(k))
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// GetExtraAs decodes the unknown field key of v into a T.
+func GetExtraAs[T any](v interface {
+	Extra(key string) (json.RawMessage, bool)
+}, key string) (T, error) {
+	var out T
+	raw, ok := v.Extra(key)
+	if !ok {
+		return out, errors.Wrapf(ErrExtraNotFound, "%q", key)
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, errors.Wrapf(err, "decode extra field %q", key)
+	}
+	return out, nil
+}
+
+type jsonField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+var (
+	extrasType    = reflect.TypeOf(Extras{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	// jsonFieldCache maps struct types to their []jsonField.
+	jsonFieldCache sync.Map
+)
+
+// jsonFields returns the fields encoding/json encodes for the struct type t,
+// in order.
+func jsonFields(t reflect.Type) []jsonField {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.([]jsonField)
+	}
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type == extrasType {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, index: i, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+	}
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
+
+// lookupField finds the field key decodes into: an exact match, otherwise a
+// case-insensitive one, as encoding/json does.
+func lookupField(fields []jsonField, key string) (int, bool) {
+	for i, f := range fields {
+		if f.name == key {
+			return i, true
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// decodeWithExtra decodes data into v, a pointer to a struct type without
+// custom unmarshaling, and returns the Extras to embed in it.
+func decodeWithExtra(data []byte, v any) (Extras, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return Extras{}, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("null")) {
+		return Extras{}, nil
+	}
+	rv := reflect.ValueOf(v).Elem()
+	fields := jsonFields(rv.Type())
+
+	// The canonical encoding has the fields in order, without empty omitempty
+	// ones.
+	var expected []int
+	for i, f := range fields {
+		if !f.omitEmpty || !isEmptyValue(rv.Field(f.index)) {
+			expected = append(expected, i)
+		}
+	}
+
+	var form, unknown bytes.Buffer
+	canonical := true
+	n := 0
+	var err error
+	members(data, func(k, value []byte) {
+		name := unquoteKey(k)
+		i, known := lookupField(fields, name)
+		if !known {
+			canonical = false
+			appendMember(&unknown, k)
+			if cerr := json.Compact(&unknown, value); cerr != nil && err == nil {
+				err = cerr
+			}
+			appendMember(&form, k)
+			form.WriteString("{}")
+			return
+		}
+
+		appendMember(&form, k)
+		if value[0] == '{' || value[0] == '[' {
+			form.WriteString("{}")
+		} else {
+			form.Write(value)
+		}
+		if canonical {
+			canonical = n < len(expected) && expected[n] == i && fields[i].name == name &&
+				(value[0] == '{' || value[0] == '[' || encodesTo(rv.Field(fields[i].index), value))
+		}
+		n++
+	})
+	if err != nil {
+		return Extras{}, err
+	}
+	if canonical && n == len(expected) {
+		return Extras{}, nil
+	}
+	return Extras{form: closeObject(&form), fields: closeObject(&unknown)}, nil
+}
+
+// encodeWithExtra encodes v, a struct without custom marshaling, with the keys
+// and unmodified scalar values of e. Fields the service didn't send follow in
+// field order when they are set.
+func encodeWithExtra(v any, e Extras) ([]byte, error) {
+	if e.form == "" {
+		return json.Marshal(v)
+	}
+	rv := reflect.ValueOf(v)
+	fields := jsonFields(rv.Type())
+	sent := make([]bool, len(fields))
+
+	var buf bytes.Buffer
+	var err error
+	write := func(k []byte, value []byte, encErr error) {
+		if encErr != nil {
+			if err == nil {
+				err = encErr
+			}
+			return
+		}
+		appendMember(&buf, k)
+		buf.Write(value)
+	}
+	members([]byte(e.form), func(k, value []byte) {
+		name := unquoteKey(k)
+		i, known := lookupField(fields, name)
+		if !known {
+			raw, _ := e.Extra(name)
+			write(k, raw, nil)
+			return
+		}
+		sent[i] = true
+		fv := rv.Field(fields[i].index)
+		if value[0] != '{' && value[0] != '[' && decodesTo(value, fv) {
+			write(k, value, nil)
+			return
+		}
+		data, encErr := json.Marshal(fv.Interface())
+		write(k, data, encErr)
+	})
+	for i, f := range fields {
+		fv := rv.Field(f.index)
+		if sent[i] || fv.IsZero() || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		name, _ := json.Marshal(f.name)
+		data, encErr := json.Marshal(fv.Interface())
+		write(name, data, encErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(closeObject(&buf)), nil
+}
+
+// encodesTo reports whether v encodes to raw.
+func encodesTo(v reflect.Value, raw []byte) bool {
+	custom := v.Type().Implements(marshalerType) || reflect.PointerTo(v.Type()).Implements(marshalerType)
+	switch {
+	case custom:
+	case v.Kind() == reflect.String:
+		if bytes.ContainsAny(raw, "\\<>&\u2028\u2029") {
+			break
+		}
+		return len(raw) >= 2 && raw[0] == '"' && string(raw[1:len(raw)-1]) == v.String()
+	case v.CanInt():
+		return string(raw) == strconv.FormatInt(v.Int(), 10)
+	case v.Kind() == reflect.Bool:
+		return string(raw) == strconv.FormatBool(v.Bool())
+	}
+	data, err := json.Marshal(v.Interface())
+	return err == nil && bytes.Equal(data, raw)
+}
+
+// decodesTo reports whether raw decodes to v, i.e. v is unmodified.
+func decodesTo(raw []byte, v reflect.Value) bool {
+	decoded := reflect.New(v.Type())
+	if err := json.Unmarshal(raw, decoded.Interface()); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(decoded.Elem().Interface(), v.Interface())
+}
+
+// isEmptyValue matches the omitempty rule of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// appendMember starts the next member of an object being written to buf.
+func appendMember(buf *bytes.Buffer, key []byte) {
+	if buf.Len() == 0 {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte(',')
+	}
+	buf.Write(key)
+	buf.WriteByte(':')
+}
+
+func closeObject(buf *bytes.Buffer) string {
+	if buf.Len() == 0 {
+		return "{}"
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// members calls fn with the raw key and value of every member of the object
+// data, which must be valid JSON. Anything but an object has no members.
+func members(data []byte, fn func(key, value []byte)) {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return
+	}
+	i = skipSpace(data, i+1)
+	for i < len(data) && data[i] != '}' {
+		start := i
+		i = skipString(data, i)
+		key := data[start:i]
+		i = skipSpace(data, skipSpace(data, i)+1)
+		start = i
+		i = skipValue(data, i)
+		fn(key, data[start:i])
+		i = skipSpace(data, i)
+		if i < len(data) && data[i] == ',' {
+			i = skipSpace(data, i+1)
+		}
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipString returns the index after the string starting at i.
+func skipString(data []byte, i int) int {
+	for i++; i < len(data) && data[i] != '"'; i++ {
+		if data[i] == '\\' {
+			i++
+		}
+	}
+	return i + 1
+}
+
+// skipValue returns the index after the value starting at i.
+func skipValue(data []byte, i int) int {
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				i = skipString(data, i)
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+		return i
+	}
+	for i < len(data) && !strings.ContainsRune(",}] \t\n\r", rune(data[i])) {
+		i++
+	}
+	return i
+}
+
+func unquoteKey(raw []byte) string {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1])
+	}
+	var s string
+	_ = json.Unmarshal(raw, &s)
+	return s
+}
+
+func (s *AssetSchedule) UnmarshalJSON(data []byte) error {
+	type plain AssetSchedule
+	extras, err := decodeWithExtra(data, (*plain)(s))
+	s.Extras = extras
+	return err
+}
+
+func (s AssetSchedule) MarshalJSON() ([]byte, error) {
+	type plain AssetSchedule
+	return encodeWithExtra(plain(s), s.Extras)
+}
+
+func (s *AssetsSchedule) UnmarshalJSON(data []byte) error {
+	type plain AssetsSchedule
+	extras, err := decodeWithExtra(data, (*plain)(s))
+	s.Extras = extras
+	return err
+}
+
+func (s AssetsSchedule) MarshalJSON() ([]byte, error) {
+	type plain AssetsSchedule
+	return encodeWithExtra(plain(s), s.Extras)
+}
+
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	type plain Asset
+	extras, err := decodeWithExtra(data, (*plain)(a))
+	a.Extras = extras
+	return err
+}
+
+func (a Asset) MarshalJSON() ([]byte, error) {
+	type plain Asset
+	return encodeWithExtra(plain(a), a.Extras)
+}
+
+func (o *OracleConfig) UnmarshalJSON(data []byte) error {
+	type plain OracleConfig
+	extras, err := decodeWithExtra(data, (*plain)(o))
+	o.Extras = extras
+	return err
+}
+
+func (o OracleConfig) MarshalJSON() ([]byte, error) {
+	type plain OracleConfig
+	return encodeWithExtra(plain(o), o.Extras)
+}
+
+func (a *AssetConfig) UnmarshalJSON(data []byte) error {
+	type plain AssetConfig
+	extras, err := decodeWithExtra(data, (*plain)(a))
+	a.Extras = extras
+	return err
+}
+
+func (a AssetConfig) MarshalJSON() ([]byte, error) {
+	type plain AssetConfig
+	return encodeWithExtra(plain(a), a.Extras)
+}
+
+func (p *VPIParams) UnmarshalJSON(data []byte) error {
+	type plain VPIParams
+	extras, err := decodeWithExtra(data, (*plain)(p))
+	p.Extras = extras
+	return err
+}
+
+func (p VPIParams) MarshalJSON() ([]byte, error) {
+	type plain VPIParams
+	return encodeWithExtra(plain(p), p.Extras)
+}
+
+func (a *CollateralAsset) UnmarshalJSON(data []byte) error {
+	type plain CollateralAsset
+	extras, err := decodeWithExtra(data, (*plain)(a))
+	a.Extras = extras
+	return err
+}
+
+func (a CollateralAsset) MarshalJSON() ([]byte, error) {
+	type plain CollateralAsset
+	return encodeWithExtra(plain(a), a.Extras)
+}
+
+func (m *Market) UnmarshalJSON(data []byte) error {
+	type plain Market
+	extras, err := decodeWithExtra(data, (*plain)(m))
+	m.Extras = extras
+	return err
+}
+
+func (m Market) MarshalJSON() ([]byte, error) {
+	type plain Market
+	return encodeWithExtra(plain(m), m.Extras)
+}
+
+func (v *Vault) UnmarshalJSON(data []byte) error {
+	type plain Vault
+	extras, err := decodeWithExtra(data, (*plain)(v))
+	v.Extras = extras
+	return err
+}
+
+func (v Vault) MarshalJSON() ([]byte, error) {
+	type plain Vault
+	return encodeWithExtra(plain(v), v.Extras)
+}
+
+func (c *AppConfig) UnmarshalJSON(data []byte) error {
+	type plain AppConfig
+	extras, err := decodeWithExtra(data, (*plain)(c))
+	c.Extras = extras
+	return err
+}
+
+func (c AppConfig) MarshalJSON() ([]byte, error) {
+	type plain AppConfig
+	return encodeWithExtra(plain(c), c.Extras)
+}
+
+func (b *Builder) UnmarshalJSON(data []byte) error {
+	type plain Builder
+	extras, err := decodeWithExtra(data, (*plain)(b))
+	b.Extras = extras
+	return err
+}
+
+func (b Builder) MarshalJSON() ([]byte, error) {
+	type plain Builder
+	return encodeWithExtra(plain(b), b.Extras)
+}
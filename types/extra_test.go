@@ -0,0 +1,219 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/test-go/testify/require"
+)
+
+const appConfigWithExtras = `{
+	"composedAt":"2024-01-01T00:00:00Z",
+	"assets":[{"name":"USDT","decimals":6,"assetId":"usdt-id","issuer":{"name":"Tether"}}],
+	"openedMarkets":[{"name":"BTC/USDT","ticker":"BTC","address":"market-btc","vaultAddress":"vault-usdt","imageLink":"","quoteAsset":"USDT","quoteAssetId":"usdt-id","baseAsset":"BTC","settlementToken":"","tags":["crypto"],"type":"perp","maxLeverage":50,"fundingInterval":"1h"}],
+	"liquiditySources":[{"asset":{"name":"USDT","decimals":6,"assetId":"usdt-id","isStable":true},"vaultAddress":"vault-usdt","quoteAssetId":"usdt-id","lpJettonMaster":"lp-usdt","tvlCap":"1000000"}],
+	"builders":[{"builder":"b","rebate":"0.1","discount":"","active":true,"tier":2}],
+	"region":"eu"
+}`
+
+func compact(t *testing.T, s string) string {
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, []byte(s)))
+	return buf.String()
+}
+
+func TestExtrasRoundTrip(t *testing.T) {
+	var cfg AppConfig
+	require.NoError(t, json.Unmarshal([]byte(appConfigWithExtras), &cfg))
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, compact(t, appConfigWithExtras), string(data))
+
+	var again AppConfig
+	require.NoError(t, json.Unmarshal(data, &again))
+	again2, err := json.Marshal(&again)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(again2))
+
+	// Unknown fields stay where the service put them.
+	data, err = json.Marshal(cfg.OpenedMarkets[0])
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"BTC/USDT","ticker":"BTC","address":"market-btc","vaultAddress":"vault-usdt","imageLink":"","quoteAsset":"USDT","quoteAssetId":"usdt-id","baseAsset":"BTC","settlementToken":"","tags":["crypto"],"type":"perp","maxLeverage":50,"fundingInterval":"1h"}`, string(data))
+}
+
+func TestExtrasRoundTripKeepsForms(t *testing.T) {
+	const configs = `[{"name":"BTC","index":0,"oracles":[` +
+		`{"feedId":112,"priority":0,"weight":0.60,"stalenessThreshold":0},` +
+		`{"region":"eu","provider":"pyth","feedId":"0xe62d","weight":"1"}` +
+		`],"vpi":{"k":"1","spread":"2","curve":"v2"}}]`
+
+	var decoded []AssetConfig
+	require.NoError(t, json.Unmarshal([]byte(configs), &decoded))
+	require.Equal(t, FeedId("112"), decoded[0].Oracles[0].FeedId)
+	require.Equal(t, 0, *decoded[0].Oracles[0].Priority)
+	require.Equal(t, "0.60", decoded[0].Oracles[0].Weight.String())
+
+	data, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	require.Equal(t, configs, string(data))
+
+	// A modified field is encoded from its value, the others keep their form.
+	decoded[0].Oracles[1].Provider = "pyth-lazer"
+	weight := NewDecimal(big.NewInt(5), 1)
+	decoded[0].Oracles[0].Weight = &weight
+	data, err = json.Marshal(decoded[0].Oracles)
+	require.NoError(t, err)
+	require.Equal(t, `[{"feedId":112,"priority":0,"weight":"0.5","stalenessThreshold":0},`+
+		`{"region":"eu","provider":"pyth-lazer","feedId":"0xe62d","weight":"1"}]`, string(data))
+}
+
+func TestExtrasKeepTypesComparable(t *testing.T) {
+	var a Asset
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"BTC","index":0,"type":"crypto"}`), &a))
+	require.True(t, a == Asset{Name: "BTC", Type: "crypto"})
+
+	var c CollateralAsset
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"USDT","decimals":6,"assetId":"usdt-id","isStable":true}`), &c))
+	copied := c
+	require.True(t, c == copied)
+
+	var p VPIParams
+	require.NoError(t, json.Unmarshal([]byte(`{"k":"1"}`), &p))
+	seen := map[any]bool{a: true, c: true, p: true}
+	require.True(t, seen[a] && seen[copied] && seen[p])
+
+	// Decodes of the same payload are equal whatever its form.
+	const payload = `{"isStable":true,"assetId":"usdt-id","decimals":6,"name":"USDT","issuer":"Tether"}`
+	var first, second CollateralAsset
+	require.NoError(t, json.Unmarshal([]byte(payload), &first))
+	require.NoError(t, json.Unmarshal([]byte(payload), &second))
+	require.True(t, first == second)
+	require.False(t, first == c)
+}
+
+func TestExtrasSurviveModification(t *testing.T) {
+	var m Market
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"BTC/USDT","maxLeverage":50}`), &m))
+	m.Name = "ETH/USDT"
+
+	data, err := json.Marshal(&m)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"ETH/USDT","maxLeverage":50}`, string(data))
+
+	m.Type = "perp"
+	data, err = json.Marshal(&m)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"ETH/USDT","maxLeverage":50,"type":"perp"}`, string(data))
+}
+
+func TestExtraAccessors(t *testing.T) {
+	var cfg AppConfig
+	require.NoError(t, json.Unmarshal([]byte(appConfigWithExtras), &cfg))
+	market := cfg.OpenedMarkets[0]
+
+	raw, ok := market.Extra("maxLeverage")
+	require.True(t, ok)
+	require.Equal(t, "50", string(raw))
+	_, ok = market.Extra("name")
+	require.False(t, ok)
+	require.Equal(t, []string{"fundingInterval", "maxLeverage"}, market.ExtraKeys())
+
+	leverage, err := GetExtraAs[int](market, "maxLeverage")
+	require.NoError(t, err)
+	require.Equal(t, 50, leverage)
+
+	issuer, err := GetExtraAs[struct{ Name string }](cfg.CollateralAssets[0], "issuer")
+	require.NoError(t, err)
+	require.Equal(t, "Tether", issuer.Name)
+
+	stable, err := GetExtraAs[bool](cfg.Vaults[0].Asset, "isStable")
+	require.NoError(t, err)
+	require.True(t, stable)
+
+	region, err := GetExtraAs[string](&cfg, "region")
+	require.NoError(t, err)
+	require.Equal(t, "eu", region)
+
+	_, err = GetExtraAs[int](cfg.Builders[0], "missing")
+	require.True(t, errors.Is(err, ErrExtraNotFound))
+	_, err = GetExtraAs[int](market, "fundingInterval")
+	require.EqualError(t, err, `decode extra field "fundingInterval": json: cannot unmarshal string into Go value of type int`)
+}
+
+func TestNoExtrasDecodesLikeLiteral(t *testing.T) {
+	var a Asset
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"BTC","index":0,"type":"crypto"}`), &a))
+	require.Equal(t, Asset{Name: "BTC", Type: "crypto"}, a)
+	require.Empty(t, a.ExtraKeys())
+
+	var cfg AssetConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"BTC","vpi":{"k":"1","curve":"v2"},"oracles":[{"Provider":"pyth","PRIORITY":2,"Region":"eu"}]}`), &cfg))
+	curve, err := GetExtraAs[string](cfg.VPI, "curve")
+	require.NoError(t, err)
+	require.Equal(t, "v2", curve)
+	// Field names match case-insensitively, as encoding/json does.
+	require.Equal(t, 2, *cfg.Oracles[0].Priority)
+	require.Equal(t, []string{"Region"}, cfg.Oracles[0].ExtraKeys())
+
+	require.Error(t, json.Unmarshal([]byte(`{"name":1}`), &a))
+}
+
+// benchmarkPayloads returns a config and asset configs of a typical size, with
+// keys in the service's order rather than the struct's.
+func benchmarkPayloads() (config, assetConfigs []byte) {
+	var b bytes.Buffer
+	b.WriteString(`{"composedAt":"2024-01-01T00:00:00Z","region":"eu","openedMarkets":[`)
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"address":"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF","name":"BTC/USDT","ticker":"BTC","vaultAddress":"vault-usdt","imageLink":"https://example.com/btc.png","quoteAsset":"USDT","quoteAssetId":"usdt-id","baseAsset":"BTC","settlementToken":"","tags":["crypto","major"],"type":"perp","maxLeverage":50}`)
+	}
+	b.WriteString(`],"liquiditySources":[`)
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"vaultAddress":"vault-usdt","asset":{"name":"USDT","decimals":6,"assetId":"usdt-id","isStable":true},"quoteAssetId":"usdt-id","lpJettonMaster":"lp-usdt","tvlCap":"1000000"}`)
+	}
+	b.WriteString(`],"builders":[`)
+	for i := 0; i < 20; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"builder":"b","rebate":"0.1","discount":"0.05","active":true,"tier":2}`)
+	}
+	b.WriteString(`]}`)
+	config = append([]byte(nil), b.Bytes()...)
+
+	b.Reset()
+	b.WriteByte('[')
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"name":"BTC","index":0,"type":"crypto","description":"Bitcoin","vpi":{"spread":"1000","k":"2000","marketDepthLong":"100000000","marketDepthShort":"200000000"},"scheduleTimeZone":"","schedule":"","holidays":"","nonHolidays":"","oracles":[{"provider":"pyth","feedId":112,"priority":0,"weight":0.60},{"provider":"redstone","feedId":"0xe62d","weight":"0.4","stalenessThreshold":30}]}`)
+	}
+	b.WriteByte(']')
+	return config, b.Bytes()
+}
+
+func BenchmarkDecodeConfig(b *testing.B) {
+	config, assetConfigs := benchmarkPayloads()
+	b.SetBytes(int64(len(config) + len(assetConfigs)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var cfg AppConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			b.Fatal(err)
+		}
+		var confs []AssetConfig
+		if err := json.Unmarshal(assetConfigs, &confs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,7 +1,20 @@
+// Package types holds the values served by the config service.
+//
+// The types decoded from the service embed Extras, which keeps the fields this
+// module doesn't know and the form the service encoded the known ones in, so
+// that json.Marshal reproduces what was received. As a result == compares the
+// received form too: two decodes of the same payload are ==, and a decode is ==
+// to a literal of the same fields only when the payload was in the canonical
+// form json.Marshal produces for that literal, with no unknown fields.
 package types
 
 import (
+	"encoding/json"
 	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 type ScheduleType string
@@ -21,6 +34,8 @@ type AssetSchedule struct {
 	Holidays         string       `json:"holidays"`
 	NonHolidays      string       `json:"nonHolidays"`
 	ScheduleType     ScheduleType `json:"scheduleType,omitempty"`
+
+	Extras
 }
 
 func (s *AssetSchedule) GetScheduleType() ScheduleType {
@@ -37,16 +52,48 @@ func (s *AssetSchedule) IsEffective() bool {
 
 type AssetsSchedule struct {
 	Schedules map[string]*AssetSchedule `json:"schedules"`
+
+	Extras
 }
 
 type Asset struct {
 	Name  string `json:"name"`
 	Index int    `json:"index"`
 	Type  string `json:"type"`
+
+	Extras
 }
 
 type OracleConfig struct {
 	Provider string `json:"provider"`
+	FeedId   FeedId `json:"feedId,omitempty"`
+	// Priority orders fallbacks, lowest first. Nil means unset.
+	Priority           *int     `json:"priority,omitempty"`
+	Weight             *Decimal `json:"weight,omitempty"`
+	StalenessThreshold *Decimal `json:"stalenessThreshold,omitempty"`
+
+	Extras
+}
+
+// FeedId identifies a price feed. Providers send it as a string or a number;
+// it is always encoded as a string.
+type FeedId string
+
+func (f *FeedId) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*f = FeedId(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = FeedId(n)
+	return nil
 }
 
 type AssetConfig struct {
@@ -60,6 +107,8 @@ type AssetConfig struct {
 	Holidays         string         `json:"holidays"`
 	NonHolidays      string         `json:"nonHolidays"`
 	Oracles          []OracleConfig `json:"oracles"`
+
+	Extras
 }
 
 type VPIParams struct {
@@ -67,6 +116,68 @@ type VPIParams struct {
 	MarketDepthShort string `json:"marketDepthShort"`
 	Spread           string `json:"spread"`
 	K                string `json:"k"`
+
+	Extras
+}
+
+// VPIFieldError names the VPI field that failed to parse.
+type VPIFieldError struct {
+	Field string
+	Value string
+}
+
+func (e *VPIFieldError) Error() string {
+	if e.Value == "" {
+		return "vpi " + e.Field + " is empty"
+	}
+	return "vpi " + e.Field + " " + strconv.Quote(e.Value) + " is not an integer"
+}
+
+func (p VPIParams) IsEmpty() bool {
+	return p.MarketDepthLong == "" && p.MarketDepthShort == "" && p.Spread == "" && p.K == ""
+}
+
+// Parse converts every field to a big.Int. Timestamp is left to the caller.
+func (p VPIParams) Parse() (VPIParamsParsed, error) {
+	var parsed VPIParamsParsed
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   **big.Int
+	}{
+		{"marketDepthLong", p.MarketDepthLong, &parsed.MarketDepthLong},
+		{"marketDepthShort", p.MarketDepthShort, &parsed.MarketDepthShort},
+		{"spread", p.Spread, &parsed.Spread},
+		{"k", p.K, &parsed.K},
+	} {
+		n, ok := new(big.Int).SetString(f.value, 10)
+		if !ok {
+			return VPIParamsParsed{}, &VPIFieldError{Field: f.name, Value: f.value}
+		}
+		*f.dst = n
+	}
+	return parsed, nil
+}
+
+// VPIDecimals is VPIParams with every integer field as a Decimal.
+type VPIDecimals struct {
+	MarketDepthLong  Decimal
+	MarketDepthShort Decimal
+	Spread           Decimal
+	K                Decimal
+}
+
+func (p VPIParams) Decimals() (VPIDecimals, error) {
+	parsed, err := p.Parse()
+	if err != nil {
+		return VPIDecimals{}, err
+	}
+	return VPIDecimals{
+		MarketDepthLong:  NewDecimal(parsed.MarketDepthLong, 0),
+		MarketDepthShort: NewDecimal(parsed.MarketDepthShort, 0),
+		Spread:           NewDecimal(parsed.Spread, 0),
+		K:                NewDecimal(parsed.K, 0),
+	}, nil
 }
 
 type VPIParamsParsed struct {
@@ -77,10 +188,29 @@ type VPIParamsParsed struct {
 	K                *big.Int
 }
 
+// Clone returns a deep copy that shares no big.Int with p.
+func (p VPIParamsParsed) Clone() VPIParamsParsed {
+	clone := func(n *big.Int) *big.Int {
+		if n == nil {
+			return nil
+		}
+		return new(big.Int).Set(n)
+	}
+	return VPIParamsParsed{
+		Timestamp:        p.Timestamp,
+		MarketDepthLong:  clone(p.MarketDepthLong),
+		MarketDepthShort: clone(p.MarketDepthShort),
+		Spread:           clone(p.Spread),
+		K:                clone(p.K),
+	}
+}
+
 type CollateralAsset struct {
 	Name     string `json:"name"`
 	Decimals int    `json:"decimals"`
 	AssetId  string `json:"assetId"`
+
+	Extras
 }
 
 type Market struct {
@@ -95,6 +225,8 @@ type Market struct {
 	SettlementToken string   `json:"settlementToken"`
 	Tags            []string `json:"tags"`
 	Type            string   `json:"type"`
+
+	Extras
 }
 
 type Vault struct {
@@ -102,6 +234,8 @@ type Vault struct {
 	VaultAddress   string          `json:"vaultAddress"`
 	QuoteAssetId   string          `json:"quoteAssetId"`
 	LpJettonMaster string          `json:"lpJettonMaster"`
+
+	Extras
 }
 
 type AppConfig struct {
@@ -110,6 +244,19 @@ type AppConfig struct {
 	OpenedMarkets    []Market          `json:"openedMarkets"`
 	Vaults           []Vault           `json:"liquiditySources"`
 	Builders         []Builder         `json:"builders"`
+
+	Extras
+}
+
+// ComposedAtTime parses ComposedAt as RFC 3339 or as unix milliseconds.
+func (c *AppConfig) ComposedAtTime() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, c.ComposedAt); err == nil {
+		return t, nil
+	}
+	if ms, err := strconv.ParseInt(c.ComposedAt, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Time{}, errors.Errorf("unsupported composedAt %q", c.ComposedAt)
 }
 
 type Builder struct {
@@ -117,4 +264,19 @@ type Builder struct {
 	Rebate   string `json:"rebate"`
 	Discount string `json:"discount"`
 	Active   bool   `json:"active"`
+
+	Extras
+}
+
+// Snapshot is a complete set of resources fetched in one refresh.
+type Snapshot struct {
+	ComposedAt   time.Time
+	Config       *AppConfig
+	Assets       []*Asset
+	AssetConfigs []*AssetConfig
+	Schedules    map[string]*AssetSchedule
+	VPIHistory   map[string]map[int64]VPIParamsParsed
+	// CurrentVPI holds the parsed VPI of every asset config with a parseable one,
+	// with the Timestamp of the matching newest history entry, or zero.
+	CurrentVPI map[string]VPIParamsParsed
 }
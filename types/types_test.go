@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/test-go/testify/require"
 )
@@ -37,3 +38,61 @@ func TestScheduleTypeIsEffective(t *testing.T) {
 	require.False(t, ScheduleTypeInfo.IsEffective())
 	require.False(t, ScheduleType("unknown").IsEffective())
 }
+
+func TestAppConfigComposedAtTime(t *testing.T) {
+	ts, err := (&AppConfig{ComposedAt: "2024-03-10T07:30:00.123Z"}).ComposedAtTime()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 10, 7, 30, 0, 123000000, time.UTC), ts)
+
+	ts, err = (&AppConfig{ComposedAt: "1710055800123"}).ComposedAtTime()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 10, 7, 30, 0, 123000000, time.UTC), ts)
+
+	_, err = (&AppConfig{ComposedAt: "yesterday"}).ComposedAtTime()
+	require.Error(t, err)
+}
+
+func TestVPIParamsParse(t *testing.T) {
+	p, err := VPIParams{MarketDepthLong: "1000000000000000000000", MarketDepthShort: "2", Spread: "-3", K: "4"}.Parse()
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000000", p.MarketDepthLong.String())
+	require.Equal(t, "-3", p.Spread.String())
+
+	_, err = VPIParams{MarketDepthLong: "1", MarketDepthShort: "2", Spread: "3", K: "1e9"}.Parse()
+	fieldErr, ok := err.(*VPIFieldError)
+	require.True(t, ok)
+	require.Equal(t, "k", fieldErr.Field)
+	require.EqualError(t, err, `vpi k "1e9" is not an integer`)
+
+	require.True(t, VPIParams{}.IsEmpty())
+	require.False(t, VPIParams{K: "1"}.IsEmpty())
+}
+
+func TestOracleConfigJSON(t *testing.T) {
+	var configs []OracleConfig
+	err := json.Unmarshal([]byte(`[
+		{"provider":"pyth-lazer","feedId":112,"priority":0,"weight":"0.75","stalenessThreshold":1.5,"region":"eu","tags":["a"]},
+		{"provider":"pyth","feedId":"0xe62df6c8"}
+	]`), &configs)
+	require.NoError(t, err)
+
+	lazer := configs[0]
+	require.Equal(t, FeedId("112"), lazer.FeedId)
+	require.NotNil(t, lazer.Priority)
+	require.Equal(t, 0, *lazer.Priority)
+	require.Equal(t, "0.75", lazer.Weight.String())
+	require.Equal(t, "1.5", lazer.StalenessThreshold.String())
+	region, ok := lazer.Extra("region")
+	require.True(t, ok)
+	require.Equal(t, `"eu"`, string(region))
+	_, ok = lazer.Extra("provider")
+	require.False(t, ok)
+
+	pyth := configs[1]
+	require.Nil(t, pyth.Priority)
+	require.Nil(t, pyth.Weight)
+	require.Nil(t, pyth.StalenessThreshold)
+	data, err := json.Marshal(pyth)
+	require.NoError(t, err)
+	require.Equal(t, `{"provider":"pyth","feedId":"0xe62df6c8"}`, string(data))
+}
@@ -0,0 +1,165 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/storm-trade/config-discovery-client/address"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+type MarketVaultRef struct {
+	Market       string `json:"market"`
+	VaultAddress string `json:"vaultAddress"`
+}
+
+type MarketAssetRef struct {
+	Market             string `json:"market"`
+	BaseAsset          string `json:"baseAsset"`
+	MissingAsset       bool   `json:"missingAsset"`
+	MissingAssetConfig bool   `json:"missingAssetConfig"`
+}
+
+// IntegrityReport lists dangling references between the resources of a snapshot.
+type IntegrityReport struct {
+	MarketsWithoutVault       []MarketVaultRef `json:"marketsWithoutVault"`
+	MarketsWithoutAsset       []MarketAssetRef `json:"marketsWithoutAsset"`
+	AssetsWithoutConfig       []string         `json:"assetsWithoutConfig"`
+	AssetConfigsWithoutAsset  []string         `json:"assetConfigsWithoutAsset"`
+	UnusedCollateralAssets    []string         `json:"unusedCollateralAssets"`
+	SchedulesForUnknownAssets []string         `json:"schedulesForUnknownAssets"`
+}
+
+func BuildIntegrityReport(s *types.Snapshot) *IntegrityReport {
+	r := &IntegrityReport{
+		MarketsWithoutVault:       []MarketVaultRef{},
+		MarketsWithoutAsset:       []MarketAssetRef{},
+		AssetsWithoutConfig:       []string{},
+		AssetConfigsWithoutAsset:  []string{},
+		UnusedCollateralAssets:    []string{},
+		SchedulesForUnknownAssets: []string{},
+	}
+	if s == nil {
+		return r
+	}
+
+	assets := make(map[string]bool)
+	for _, a := range s.Assets {
+		assets[a.Name] = true
+	}
+	assetConfigs := make(map[string]bool)
+	for _, a := range s.AssetConfigs {
+		assetConfigs[a.Name] = true
+	}
+
+	for _, a := range s.Assets {
+		if !assetConfigs[a.Name] {
+			r.AssetsWithoutConfig = append(r.AssetsWithoutConfig, a.Name)
+		}
+	}
+	for _, a := range s.AssetConfigs {
+		if !assets[a.Name] {
+			r.AssetConfigsWithoutAsset = append(r.AssetConfigsWithoutAsset, a.Name)
+		}
+	}
+	for name := range s.Schedules {
+		if !assets[name] && !assetConfigs[name] {
+			r.SchedulesForUnknownAssets = append(r.SchedulesForUnknownAssets, name)
+		}
+	}
+
+	if s.Config != nil {
+		vaults := make(map[string]bool)
+		usedCollateral := make(map[string]bool)
+		for _, v := range s.Config.Vaults {
+			vaults[address.Canonical(v.VaultAddress)] = true
+			usedCollateral[v.Asset.Name] = true
+		}
+
+		for _, m := range s.Config.OpenedMarkets {
+			if !vaults[address.Canonical(m.VaultAddress)] {
+				r.MarketsWithoutVault = append(r.MarketsWithoutVault, MarketVaultRef{Market: m.Address, VaultAddress: m.VaultAddress})
+			}
+			if !assets[m.BaseAsset] || !assetConfigs[m.BaseAsset] {
+				r.MarketsWithoutAsset = append(r.MarketsWithoutAsset, MarketAssetRef{
+					Market:             m.Address,
+					BaseAsset:          m.BaseAsset,
+					MissingAsset:       !assets[m.BaseAsset],
+					MissingAssetConfig: !assetConfigs[m.BaseAsset],
+				})
+			}
+		}
+		for _, a := range s.Config.CollateralAssets {
+			if !usedCollateral[a.Name] {
+				r.UnusedCollateralAssets = append(r.UnusedCollateralAssets, a.Name)
+			}
+		}
+	}
+
+	sort.Strings(r.AssetsWithoutConfig)
+	sort.Strings(r.AssetConfigsWithoutAsset)
+	sort.Strings(r.UnusedCollateralAssets)
+	sort.Strings(r.SchedulesForUnknownAssets)
+	return r
+}
+
+func (r *IntegrityReport) Empty() bool {
+	return len(r.MarketsWithoutVault) == 0 &&
+		len(r.MarketsWithoutAsset) == 0 &&
+		len(r.AssetsWithoutConfig) == 0 &&
+		len(r.AssetConfigsWithoutAsset) == 0 &&
+		len(r.UnusedCollateralAssets) == 0 &&
+		len(r.SchedulesForUnknownAssets) == 0
+}
+
+// WriteText renders the report for humans, one section per problem kind.
+func (r *IntegrityReport) WriteText(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintln(w, "no integrity problems found")
+		return err
+	}
+
+	var lines []string
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d):", title, len(items)))
+		for _, item := range items {
+			lines = append(lines, "  "+item)
+		}
+	}
+
+	var items []string
+	for _, m := range r.MarketsWithoutVault {
+		items = append(items, fmt.Sprintf("%s -> vault %s", m.Market, m.VaultAddress))
+	}
+	section("Markets without vault", items)
+
+	items = nil
+	for _, m := range r.MarketsWithoutAsset {
+		var missing string
+		switch {
+		case m.MissingAsset && m.MissingAssetConfig:
+			missing = "asset and asset config"
+		case m.MissingAsset:
+			missing = "asset"
+		default:
+			missing = "asset config"
+		}
+		items = append(items, fmt.Sprintf("%s -> base asset %s (missing %s)", m.Market, m.BaseAsset, missing))
+	}
+	section("Markets without base asset", items)
+	section("Assets without asset config", r.AssetsWithoutConfig)
+	section("Asset configs without asset", r.AssetConfigsWithoutAsset)
+	section("Collateral assets unused by any vault", r.UnusedCollateralAssets)
+	section("Schedules for unknown assets", r.SchedulesForUnknownAssets)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package validation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func TestIntegrityReportOfValidSnapshot(t *testing.T) {
+	s := validSnapshot()
+	require.True(t, BuildIntegrityReport(s).Empty())
+
+	var out bytes.Buffer
+	require.NoError(t, BuildIntegrityReport(s).WriteText(&out))
+	require.Equal(t, "no integrity problems found\n", out.String())
+}
+
+func TestIntegrityReportListsDanglingReferences(t *testing.T) {
+	s := validSnapshot()
+	s.Config.OpenedMarkets = append(s.Config.OpenedMarkets, types.Market{Address: "market-doge", VaultAddress: "vault-ton", BaseAsset: "DOGE"})
+	s.Config.CollateralAssets = append(s.Config.CollateralAssets, types.CollateralAsset{Name: "TON"})
+	s.Assets = append(s.Assets, &types.Asset{Name: "ETH", Index: 1})
+	s.AssetConfigs = append(s.AssetConfigs, &types.AssetConfig{Name: "SOL", Index: 2})
+	s.Schedules = map[string]*types.AssetSchedule{"BTC": {}, "SPCX": {}}
+
+	r := BuildIntegrityReport(s)
+	require.Equal(t, []MarketVaultRef{{Market: "market-doge", VaultAddress: "vault-ton"}}, r.MarketsWithoutVault)
+	require.Equal(t, []MarketAssetRef{{Market: "market-doge", BaseAsset: "DOGE", MissingAsset: true, MissingAssetConfig: true}}, r.MarketsWithoutAsset)
+	require.Equal(t, []string{"ETH"}, r.AssetsWithoutConfig)
+	require.Equal(t, []string{"SOL"}, r.AssetConfigsWithoutAsset)
+	require.Equal(t, []string{"TON"}, r.UnusedCollateralAssets)
+	require.Equal(t, []string{"SPCX"}, r.SchedulesForUnknownAssets)
+
+	var out bytes.Buffer
+	require.NoError(t, r.WriteText(&out))
+	require.Contains(t, out.String(), "market-doge -> base asset DOGE (missing asset and asset config)")
+	require.Contains(t, out.String(), "Schedules for unknown assets (1):\n  SPCX\n")
+}
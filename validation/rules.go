@@ -0,0 +1,225 @@
+package validation
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/storm-trade/config-discovery-client/address"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+const (
+	RuleReferentialIntegrity = "referential-integrity"
+	RuleDuplicateKeys        = "duplicate-keys"
+	RuleParseableNumbers     = "parseable-numbers"
+	RuleNonEmptyAddresses    = "non-empty-addresses"
+)
+
+// Default returns the built-in rules applied to every refresh.
+func Default() []Validator {
+	return []Validator{
+		ReferentialIntegrity(),
+		DuplicateKeys(),
+		ParseableNumbers(),
+		NonEmptyAddresses(),
+		ScheduleConsistency(),
+		TimeZones(),
+		VPIConsistency(),
+	}
+}
+
+func ReferentialIntegrity() Validator {
+	return Func(RuleReferentialIntegrity, func(s *types.Snapshot) []Violation {
+		if s.Config == nil {
+			return nil
+		}
+
+		var out []Violation
+		vaults := make(map[string]bool)
+		for _, v := range s.Config.Vaults {
+			vaults[address.Canonical(v.VaultAddress)] = true
+		}
+		collateral := make(map[string]bool)
+		for _, a := range s.Config.CollateralAssets {
+			collateral[a.Name] = true
+		}
+
+		for _, m := range s.Config.OpenedMarkets {
+			if m.VaultAddress != "" && !vaults[address.Canonical(m.VaultAddress)] {
+				out = append(out, Violation{
+					Subject: "market " + m.Address,
+					Message: fmt.Sprintf("vault %s does not exist", m.VaultAddress),
+				})
+			}
+		}
+		for _, v := range s.Config.Vaults {
+			if !collateral[v.Asset.Name] {
+				out = append(out, Violation{
+					Subject: "vault " + v.VaultAddress,
+					Message: fmt.Sprintf("collateral asset %s does not exist", v.Asset.Name),
+				})
+			}
+		}
+		return out
+	})
+}
+
+// DuplicateKeys reports keys claimed more than once. Asset indexes identify
+// assets on chain, so a shared one is an error. Other keys are warnings: the
+// client indexes every one of them and leaves whether the snapshot is applied
+// to its collision policy.
+func DuplicateKeys() Validator {
+	return Func(RuleDuplicateKeys, func(s *types.Snapshot) []Violation {
+		var out []Violation
+		report := func(kind string, seen map[string]int, severity Severity) {
+			keys := make([]string, 0, len(seen))
+			for k, n := range seen {
+				if n > 1 {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				out = append(out, Violation{
+					Severity: severity,
+					Subject:  kind + " " + k,
+					Message:  fmt.Sprintf("key is used %d times", seen[k]),
+				})
+			}
+		}
+
+		assetNames, assetIndexes := map[string]int{}, map[string]int{}
+		for _, a := range s.Assets {
+			assetNames[a.Name]++
+			assetIndexes[fmt.Sprint(a.Index)]++
+		}
+		report("asset name", assetNames, SeverityWarning)
+		report("asset index", assetIndexes, SeverityError)
+
+		configNames, configIndexes := map[string]int{}, map[string]int{}
+		for _, a := range s.AssetConfigs {
+			configNames[a.Name]++
+			configIndexes[fmt.Sprint(a.Index)]++
+		}
+		report("asset config name", configNames, SeverityWarning)
+		report("asset config index", configIndexes, SeverityError)
+
+		if s.Config != nil {
+			markets, vaults, collateral := map[string]int{}, map[string]int{}, map[string]int{}
+			for _, m := range s.Config.OpenedMarkets {
+				markets[address.Canonical(m.Address)]++
+			}
+			for _, v := range s.Config.Vaults {
+				vaults[address.Canonical(v.VaultAddress)]++
+			}
+			for _, a := range s.Config.CollateralAssets {
+				collateral[a.Name]++
+			}
+			report("market address", markets, SeverityWarning)
+			report("vault address", vaults, SeverityWarning)
+			report("collateral asset", collateral, SeverityWarning)
+		}
+		return out
+	})
+}
+
+func ParseableNumbers() Validator {
+	return Func(RuleParseableNumbers, func(s *types.Snapshot) []Violation {
+		var out []Violation
+		integer := func(subject, field, value string) {
+			if value == "" {
+				return
+			}
+			n, ok := new(big.Int).SetString(value, 10)
+			if !ok {
+				out = append(out, Violation{Subject: subject, Message: fmt.Sprintf("%s %q is not an integer", field, value)})
+				return
+			}
+			if n.Sign() < 0 {
+				out = append(out, Violation{Subject: subject, Message: fmt.Sprintf("%s %s is negative", field, value)})
+			}
+		}
+		decimal := func(subject, field, value string) {
+			if value == "" {
+				return
+			}
+			if _, err := types.ParseDecimal(value); err != nil {
+				out = append(out, Violation{Subject: subject, Message: fmt.Sprintf("%s %q is not a decimal", field, value)})
+			}
+		}
+
+		for _, a := range s.AssetConfigs {
+			subject := "asset config " + a.Name
+			integer(subject, "vpi.marketDepthLong", a.VPI.MarketDepthLong)
+			integer(subject, "vpi.marketDepthShort", a.VPI.MarketDepthShort)
+			integer(subject, "vpi.spread", a.VPI.Spread)
+			integer(subject, "vpi.k", a.VPI.K)
+		}
+
+		names := make([]string, 0, len(s.VPIHistory))
+		for name := range s.VPIHistory {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			timestamps := make([]int64, 0, len(s.VPIHistory[name]))
+			for ts := range s.VPIHistory[name] {
+				timestamps = append(timestamps, ts)
+			}
+			sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+			for _, ts := range timestamps {
+				p := s.VPIHistory[name][ts]
+				subject := fmt.Sprintf("vpi history %s@%d", name, ts)
+				for _, f := range []struct {
+					name  string
+					value *big.Int
+				}{
+					{"marketDepthLong", p.MarketDepthLong},
+					{"marketDepthShort", p.MarketDepthShort},
+					{"spread", p.Spread},
+					{"k", p.K},
+				} {
+					if f.value != nil && f.value.Sign() < 0 {
+						out = append(out, Violation{Subject: subject, Message: fmt.Sprintf("%s %s is negative", f.name, f.value)})
+					}
+				}
+			}
+		}
+
+		if s.Config != nil {
+			for _, b := range s.Config.Builders {
+				decimal("builder "+b.Builder, "rebate", b.Rebate)
+				decimal("builder "+b.Builder, "discount", b.Discount)
+			}
+		}
+		return out
+	})
+}
+
+func NonEmptyAddresses() Validator {
+	return Func(RuleNonEmptyAddresses, func(s *types.Snapshot) []Violation {
+		if s.Config == nil {
+			return nil
+		}
+
+		var out []Violation
+		require := func(subject, field, value string) {
+			if value == "" {
+				out = append(out, Violation{Subject: subject, Message: field + " is empty"})
+			}
+		}
+		for _, m := range s.Config.OpenedMarkets {
+			require("market "+m.Name, "address", m.Address)
+			require("market "+m.Name, "vaultAddress", m.VaultAddress)
+		}
+		for _, v := range s.Config.Vaults {
+			require("vault "+v.Asset.Name, "vaultAddress", v.VaultAddress)
+			require("vault "+v.Asset.Name, "lpJettonMaster", v.LpJettonMaster)
+		}
+		for i, b := range s.Config.Builders {
+			require(fmt.Sprintf("builder #%d", i), "builder", b.Builder)
+		}
+		return out
+	})
+}
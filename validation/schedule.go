@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/storm-trade/config-discovery-client/schedule"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+const (
+	RuleScheduleConsistency = "schedule-consistency"
+	RuleTimeZones           = "time-zones"
+)
+
+// ScheduleConsistency warns about assets whose /assets-schedule entry and asset
+// config disagree. The refresh still applies, using schedule.Resolve precedence.
+func ScheduleConsistency() Validator {
+	return Func(RuleScheduleConsistency, func(s *types.Snapshot) []Violation {
+		var out []Violation
+		for _, m := range schedule.Mismatches(s.Schedules, s.AssetConfigs) {
+			out = append(out, Violation{
+				Severity: SeverityWarning,
+				Subject:  "asset " + m.Asset,
+				Asset:    m.Asset,
+				Message:  fmt.Sprintf("%s differs: assets-schedule %q, asset config %q", m.Field, m.ScheduleValue, m.ConfigValue),
+			})
+		}
+		return out
+	})
+}
+
+// TimeZones warns about every schedule time zone that can't be loaded, in
+// either /assets-schedule or the asset configs.
+func TimeZones() Validator {
+	return Func(RuleTimeZones, func(s *types.Snapshot) []Violation {
+		var out []Violation
+		check := func(asset, source, zone string) {
+			if zone == "" {
+				return
+			}
+			if _, err := schedule.LoadLocation(zone); err != nil {
+				out = append(out, Violation{
+					Severity: SeverityWarning,
+					Subject:  "asset " + asset,
+					Asset:    asset,
+					Message:  fmt.Sprintf("unknown time zone %q in %s", zone, source),
+				})
+			}
+		}
+
+		names := make([]string, 0, len(s.Schedules))
+		for name := range s.Schedules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if entry := s.Schedules[name]; entry != nil {
+				check(name, "assets-schedule", entry.ScheduleTimeZone)
+			}
+		}
+		for _, c := range s.AssetConfigs {
+			check(c.Name, "asset config", c.ScheduleTimeZone)
+		}
+		return out
+	})
+}
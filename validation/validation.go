@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Subject  string   `json:"subject"`
+	Asset    string   `json:"asset,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Rule, v.Subject, v.Message)
+}
+
+// Validator inspects a candidate snapshot before it is applied. Violations with
+// SeverityError cause the whole snapshot to be rejected.
+type Validator interface {
+	Name() string
+	Validate(s *types.Snapshot) []Violation
+}
+
+type funcValidator struct {
+	name string
+	fn   func(s *types.Snapshot) []Violation
+}
+
+func (f funcValidator) Name() string {
+	return f.name
+}
+
+func (f funcValidator) Validate(s *types.Snapshot) []Violation {
+	return f.fn(s)
+}
+
+func Func(name string, fn func(s *types.Snapshot) []Violation) Validator {
+	return funcValidator{name: name, fn: fn}
+}
+
+// Error lists every error-level violation of a rejected snapshot.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("%d config violations: %s", len(e.Violations), strings.Join(parts, "; "))
+}
+
+// Run applies every validator and returns the warnings, or an *Error when at
+// least one error-level violation was found.
+func Run(s *types.Snapshot, validators ...Validator) ([]Violation, error) {
+	var warnings, errs []Violation
+	for _, v := range validators {
+		for _, violation := range v.Validate(s) {
+			if violation.Rule == "" {
+				violation.Rule = v.Name()
+			}
+			if violation.Severity == "" {
+				violation.Severity = SeverityError
+			}
+			if violation.Severity == SeverityError {
+				errs = append(errs, violation)
+			} else {
+				warnings = append(warnings, violation)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return warnings, &Error{Violations: errs}
+	}
+	return warnings, nil
+}
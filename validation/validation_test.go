@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func validSnapshot() *types.Snapshot {
+	usdt := types.CollateralAsset{Name: "USDT", Decimals: 6, AssetId: "usdt-id"}
+	return &types.Snapshot{
+		Config: &types.AppConfig{
+			CollateralAssets: []types.CollateralAsset{usdt},
+			OpenedMarkets: []types.Market{
+				{Name: "BTC/USDT", Address: "market-btc", VaultAddress: "vault-usdt", BaseAsset: "BTC"},
+			},
+			Vaults:   []types.Vault{{Asset: usdt, VaultAddress: "vault-usdt", LpJettonMaster: "lp-usdt"}},
+			Builders: []types.Builder{{Builder: "builder", Rebate: "0.1", Discount: "0.05"}},
+		},
+		Assets:       []*types.Asset{{Name: "BTC", Index: 0}},
+		AssetConfigs: []*types.AssetConfig{{Name: "BTC", Index: 0, VPI: types.VPIParams{Spread: "1", K: "2"}}},
+		VPIHistory: map[string]map[int64]types.VPIParamsParsed{
+			"BTC": {1: {Spread: big.NewInt(1), K: big.NewInt(1), MarketDepthLong: big.NewInt(1), MarketDepthShort: big.NewInt(1)}},
+		},
+	}
+}
+
+func TestDefaultRulesAcceptValidSnapshot(t *testing.T) {
+	warnings, err := Run(validSnapshot(), Default()...)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestDefaultRulesReportEveryViolation(t *testing.T) {
+	s := validSnapshot()
+	s.Config.OpenedMarkets[0].VaultAddress = "missing-vault"
+	s.Assets = append(s.Assets, &types.Asset{Name: "ETH", Index: 0})
+	s.AssetConfigs[0].VPI.Spread = "-1"
+	s.AssetConfigs[0].VPI.K = "abc"
+	s.VPIHistory["BTC"][1] = types.VPIParamsParsed{Spread: big.NewInt(-5)}
+	s.Config.Vaults[0].LpJettonMaster = ""
+	s.Config.Builders[0].Rebate = "ten"
+
+	warnings, err := Run(s, Default()...)
+	var vErr *Error
+	require.True(t, errors.As(err, &vErr))
+
+	rules := map[string]int{}
+	for _, v := range vErr.Violations {
+		require.Equal(t, SeverityError, v.Severity)
+		rules[v.Rule]++
+	}
+	require.Equal(t, map[string]int{
+		RuleReferentialIntegrity: 1,
+		RuleDuplicateKeys:        1,
+		RuleParseableNumbers:     4,
+		RuleNonEmptyAddresses:    1,
+	}, rules)
+	require.Empty(t, warnings)
+}
+
+func TestCustomValidatorWarnings(t *testing.T) {
+	warn := Func("no-builders", func(s *types.Snapshot) []Violation {
+		return []Violation{{Severity: SeverityWarning, Subject: "builders", Message: "builders are configured"}}
+	})
+
+	warnings, err := Run(validSnapshot(), warn)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "no-builders", warnings[0].Rule)
+}
+
+func TestScheduleConsistencyWarns(t *testing.T) {
+	s := validSnapshot()
+	s.Schedules = map[string]*types.AssetSchedule{"BTC": {ScheduleTimeZone: "UTC"}}
+	s.AssetConfigs[0].ScheduleTimeZone = "Europe/London"
+
+	warnings, err := Run(s, Default()...)
+	require.NoError(t, err)
+	require.Equal(t, []Violation{{
+		Rule:     RuleScheduleConsistency,
+		Severity: SeverityWarning,
+		Subject:  "asset BTC",
+		Asset:    "BTC",
+		Message:  `scheduleTimeZone differs: assets-schedule "UTC", asset config "Europe/London"`,
+	}}, warnings)
+}
+
+func TestTimeZonesWarnsAboutUnknownZones(t *testing.T) {
+	s := validSnapshot()
+	s.Schedules = map[string]*types.AssetSchedule{"SPCX": {ScheduleTimeZone: "America/New_York"}, "AAPL": {ScheduleTimeZone: "America/Gotham"}}
+	s.AssetConfigs[0].ScheduleTimeZone = "Mars/Olympus_Mons"
+
+	warnings, err := Run(s, TimeZones())
+	require.NoError(t, err)
+	require.Equal(t, []Violation{
+		{Rule: RuleTimeZones, Severity: SeverityWarning, Subject: "asset AAPL", Asset: "AAPL", Message: `unknown time zone "America/Gotham" in assets-schedule`},
+		{Rule: RuleTimeZones, Severity: SeverityWarning, Subject: "asset BTC", Asset: "BTC", Message: `unknown time zone "Mars/Olympus_Mons" in asset config`},
+	}, warnings)
+}
+
+func TestVPIConsistencyComparesNewestHistoryEntry(t *testing.T) {
+	s := validSnapshot()
+	s.VPIHistory["BTC"][5] = types.VPIParamsParsed{Spread: big.NewInt(1), K: big.NewInt(2), MarketDepthLong: big.NewInt(1), MarketDepthShort: big.NewInt(3)}
+	s.CurrentVPI = map[string]types.VPIParamsParsed{
+		"BTC": {Spread: big.NewInt(1), K: big.NewInt(2), MarketDepthLong: big.NewInt(1), MarketDepthShort: big.NewInt(1)},
+		"ETH": {Spread: big.NewInt(1), K: big.NewInt(2), MarketDepthLong: big.NewInt(1), MarketDepthShort: big.NewInt(1)},
+	}
+
+	warnings, err := Run(s, VPIConsistency())
+	require.NoError(t, err)
+	require.Equal(t, []Violation{{
+		Rule:     RuleVPIConsistency,
+		Severity: SeverityWarning,
+		Subject:  "asset config BTC",
+		Asset:    "BTC",
+		Message:  "vpi differs from vpi history @5: marketDepthShort 1 vs 3",
+	}}, warnings)
+
+	s.CurrentVPI["BTC"] = s.VPIHistory["BTC"][5]
+	warnings, err = Run(s, VPIConsistency())
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestParseableNumbersRejectsNonDecimalBuilderValues(t *testing.T) {
+	s := validSnapshot()
+	s.Config.Builders = append(s.Config.Builders,
+		types.Builder{Builder: "fraction", Rebate: "1/3", Discount: "0"},
+		types.Builder{Builder: "exponent", Rebate: "1e-2", Discount: "0.05"},
+	)
+
+	_, err := Run(s, ParseableNumbers())
+	var vErr *Error
+	require.True(t, errors.As(err, &vErr))
+	require.Equal(t, []Violation{{
+		Rule:     RuleParseableNumbers,
+		Severity: SeverityError,
+		Subject:  "builder fraction",
+		Message:  `rebate "1/3" is not a decimal`,
+	}}, vErr.Violations)
+}
+
+func TestReferentialIntegrityMatchesAddressRepresentations(t *testing.T) {
+	const raw = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+	s := validSnapshot()
+	s.Config.Vaults[0].VaultAddress = "EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF"
+	s.Config.OpenedMarkets[0].VaultAddress = raw
+
+	warnings, err := Run(s, ReferentialIntegrity())
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.True(t, BuildIntegrityReport(s).Empty())
+
+	s.Config.Vaults = append(s.Config.Vaults, types.Vault{Asset: s.Config.Vaults[0].Asset, VaultAddress: raw, LpJettonMaster: "lp-2"})
+	warnings, err = Run(s, DuplicateKeys())
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "vault address "+raw, warnings[0].Subject)
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+const RuleVPIConsistency = "vpi-consistency"
+
+// VPIConsistency warns about asset configs whose VPI differs from the newest
+// /vpi-history entry of the same asset.
+func VPIConsistency() Validator {
+	return Func(RuleVPIConsistency, func(s *types.Snapshot) []Violation {
+		names := make([]string, 0, len(s.CurrentVPI))
+		for name := range s.CurrentVPI {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var out []Violation
+		for _, name := range names {
+			latest, ok := latestVPI(s.VPIHistory[name])
+			if !ok {
+				continue
+			}
+			current := s.CurrentVPI[name]
+			var diffs []string
+			for _, f := range []struct {
+				name           string
+				config, latest *big.Int
+			}{
+				{"marketDepthLong", current.MarketDepthLong, latest.MarketDepthLong},
+				{"marketDepthShort", current.MarketDepthShort, latest.MarketDepthShort},
+				{"spread", current.Spread, latest.Spread},
+				{"k", current.K, latest.K},
+			} {
+				if f.config == nil || f.latest == nil || f.config.Cmp(f.latest) == 0 {
+					continue
+				}
+				diffs = append(diffs, fmt.Sprintf("%s %s vs %s", f.name, f.config, f.latest))
+			}
+			if len(diffs) == 0 {
+				continue
+			}
+			out = append(out, Violation{
+				Severity: SeverityWarning,
+				Subject:  "asset config " + name,
+				Asset:    name,
+				Message:  fmt.Sprintf("vpi differs from vpi history @%d: %s", latest.Timestamp, strings.Join(diffs, ", ")),
+			})
+		}
+		return out
+	})
+}
+
+func latestVPI(h map[int64]types.VPIParamsParsed) (types.VPIParamsParsed, bool) {
+	var latest types.VPIParamsParsed
+	found := false
+	for ts, p := range h {
+		if !found || ts > latest.Timestamp {
+			latest, found = p, true
+			latest.Timestamp = ts
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,110 @@
+package vpi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// TimeLayout is the ISO-8601 form of exported timestamps, in UTC with
+// millisecond precision.
+const TimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Filter selects the history to export. Empty Assets means every asset, zero
+// From or To leaves that side of the inclusive range open.
+type Filter struct {
+	Assets []string
+	From   time.Time
+	To     time.Time
+}
+
+// Record is one exported history entry.
+type Record struct {
+	Asset            string `json:"asset"`
+	TimestampMs      int64  `json:"timestampMs"`
+	Time             string `json:"time"`
+	MarketDepthLong  string `json:"marketDepthLong"`
+	MarketDepthShort string `json:"marketDepthShort"`
+	Spread           string `json:"spread"`
+	K                string `json:"k"`
+}
+
+var csvHeader = []string{"asset", "timestampMs", "time", "marketDepthLong", "marketDepthShort", "spread", "k"}
+
+func (r Record) csv() []string {
+	return []string{r.Asset, strconv.FormatInt(r.TimestampMs, 10), r.Time, r.MarketDepthLong, r.MarketDepthShort, r.Spread, r.K}
+}
+
+// Select returns the entries of history matching f, ordered by asset and time.
+func Select(history map[string]map[int64]types.VPIParamsParsed, f Filter) []Record {
+	names := f.Assets
+	if len(names) == 0 {
+		names = make([]string, 0, len(history))
+		for name := range history {
+			names = append(names, name)
+		}
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	var records []Record
+	for _, name := range names {
+		start := len(records)
+		for ts, p := range history[name] {
+			t := time.UnixMilli(ts)
+			if (!f.From.IsZero() && t.Before(f.From)) || (!f.To.IsZero() && t.After(f.To)) {
+				continue
+			}
+			records = append(records, Record{
+				Asset:            name,
+				TimestampMs:      ts,
+				Time:             t.UTC().Format(TimeLayout),
+				MarketDepthLong:  decimal(p.MarketDepthLong),
+				MarketDepthShort: decimal(p.MarketDepthShort),
+				Spread:           decimal(p.Spread),
+				K:                decimal(p.K),
+			})
+		}
+		asset := records[start:]
+		sort.Slice(asset, func(i, j int) bool { return asset[i].TimestampMs < asset[j].TimestampMs })
+	}
+	return records
+}
+
+func decimal(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
+
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(r.csv()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONL writes one JSON object per line.
+func WriteJSONL(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
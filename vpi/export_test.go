@@ -0,0 +1,55 @@
+package vpi
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+func exportHistory() map[string]map[int64]types.VPIParamsParsed {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	entry := func(ts int64) types.VPIParamsParsed {
+		return types.VPIParamsParsed{Timestamp: ts, MarketDepthLong: huge, MarketDepthShort: big.NewInt(2), Spread: big.NewInt(-3), K: big.NewInt(4)}
+	}
+	return map[string]map[int64]types.VPIParamsParsed{
+		"LTC": {1_700_000_060_000: entry(1_700_000_060_000)},
+		"BTC": {1_700_000_120_000: entry(1_700_000_120_000), 1_700_000_000_123: entry(1_700_000_000_123)},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	h := exportHistory()
+
+	all := Select(h, Filter{})
+	require.Len(t, all, 3)
+	require.Equal(t, []string{"BTC", "BTC", "LTC"}, []string{all[0].Asset, all[1].Asset, all[2].Asset})
+	require.Equal(t, int64(1_700_000_000_123), all[0].TimestampMs)
+	require.Equal(t, "2023-11-14T22:13:20.123Z", all[0].Time)
+
+	ranged := Select(h, Filter{From: time.UnixMilli(1_700_000_060_000), To: time.UnixMilli(1_700_000_120_000)})
+	require.Len(t, ranged, 2)
+	require.Equal(t, "BTC", ranged[0].Asset)
+	require.Equal(t, int64(1_700_000_120_000), ranged[0].TimestampMs)
+
+	ltc := Select(h, Filter{Assets: []string{"LTC", "DOGE"}})
+	require.Len(t, ltc, 1)
+	require.Equal(t, "LTC", ltc[0].Asset)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, Select(exportHistory(), Filter{Assets: []string{"LTC"}})))
+	require.Equal(t, "asset,timestampMs,time,marketDepthLong,marketDepthShort,spread,k\n"+
+		"LTC,1700000060000,2023-11-14T22:14:20.000Z,123456789012345678901234567890,2,-3,4\n", buf.String())
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSONL(&buf, Select(exportHistory(), Filter{Assets: []string{"BTC"}})))
+	require.Equal(t, `{"asset":"BTC","timestampMs":1700000000123,"time":"2023-11-14T22:13:20.123Z","marketDepthLong":"123456789012345678901234567890","marketDepthShort":"2","spread":"-3","k":"4"}`+"\n"+
+		`{"asset":"BTC","timestampMs":1700000120000,"time":"2023-11-14T22:15:20.000Z","marketDepthLong":"123456789012345678901234567890","marketDepthShort":"2","spread":"-3","k":"4"}`+"\n", buf.String())
+}
@@ -0,0 +1,119 @@
+// Package impact computes the virtual price impact of a trade. It is internal
+// until the formula, rounding and Precision are checked against the contract
+// and the golden vectors are taken from the contract's tests or on-chain
+// trades; until then its results are not to be relied on.
+package impact
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+)
+
+// Precision is the fixed-point scale of rates and prices: 9 decimals.
+const Precision = 1_000_000_000
+
+var precision = big.NewInt(Precision)
+
+// ErrImpactTooLarge is returned for short trades whose impact rate would take
+// the execution price to zero or below.
+var ErrImpactTooLarge = errors.New("price impact reaches the oracle price")
+
+type Direction int
+
+const (
+	Long Direction = iota
+	Short
+)
+
+func (d Direction) String() string {
+	if d == Short {
+		return "short"
+	}
+	return "long"
+}
+
+// Trade describes a position change. Size and open interest are notional
+// amounts in the same units as the market depths, prices are scaled by
+// Precision.
+type Trade struct {
+	Direction         Direction
+	Size              *big.Int
+	LongOpenInterest  *big.Int
+	ShortOpenInterest *big.Int
+	OraclePrice       *big.Int
+}
+
+type Result struct {
+	// ImpactRate is the relative price impact scaled by Precision.
+	ImpactRate *big.Int
+	// ImpactAmount is the cost of the impact in notional units.
+	ImpactAmount   *big.Int
+	ExecutionPrice *big.Int
+}
+
+// Compute applies the virtual price impact of params to t:
+//
+//	imbalanceBefore = sideOI - oppositeOI
+//	imbalanceAfter  = imbalanceBefore + size
+//	imbalance       = max(0, (imbalanceBefore + imbalanceAfter) / 2)
+//	impactRate      = spread + k * imbalance / depth
+//	impactAmount    = size * impactRate / Precision
+//	executionPrice  = oraclePrice * (Precision ± impactRate) / Precision
+//
+// where depth is MarketDepthLong for longs and MarketDepthShort for shorts.
+// Divisions round down except the execution price, which rounds against the
+// trader: up for longs and down for shorts. A short whose impact rate reaches
+// Precision fails with ErrImpactTooLarge.
+func Compute(params types.VPIParamsParsed, t Trade) (*Result, error) {
+	if params.MarketDepthLong == nil || params.MarketDepthShort == nil || params.Spread == nil || params.K == nil {
+		return nil, errors.New("vpi params are incomplete")
+	}
+	if t.Size == nil || t.LongOpenInterest == nil || t.ShortOpenInterest == nil || t.OraclePrice == nil {
+		return nil, errors.New("trade is incomplete")
+	}
+	if t.Size.Sign() < 0 || t.LongOpenInterest.Sign() < 0 || t.ShortOpenInterest.Sign() < 0 || t.OraclePrice.Sign() <= 0 {
+		return nil, errors.New("trade amounts must not be negative and price must be positive")
+	}
+
+	depth, side, opposite := params.MarketDepthLong, t.LongOpenInterest, t.ShortOpenInterest
+	if t.Direction == Short {
+		depth, side, opposite = params.MarketDepthShort, t.ShortOpenInterest, t.LongOpenInterest
+	}
+	if depth.Sign() <= 0 {
+		return nil, errors.Errorf("%s market depth must be positive", t.Direction)
+	}
+
+	before := new(big.Int).Sub(side, opposite)
+	after := new(big.Int).Add(before, t.Size)
+	imbalance := new(big.Int).Add(before, after)
+	imbalance.Div(imbalance, big.NewInt(2))
+	if imbalance.Sign() < 0 {
+		imbalance.SetInt64(0)
+	}
+
+	rate := new(big.Int).Mul(params.K, imbalance)
+	rate.Quo(rate, depth)
+	rate.Add(rate, params.Spread)
+
+	amount := new(big.Int).Mul(t.Size, rate)
+	amount.Quo(amount, precision)
+
+	factor := new(big.Int).Set(precision)
+	if t.Direction == Long {
+		factor.Add(factor, rate)
+	} else {
+		factor.Sub(factor, rate)
+		if factor.Sign() <= 0 {
+			return nil, errors.Wrapf(ErrImpactTooLarge, "short impact rate %s", rate)
+		}
+	}
+	price := new(big.Int).Mul(t.OraclePrice, factor)
+	if t.Direction == Long {
+		price.Add(price, new(big.Int).Sub(precision, big.NewInt(1)))
+	}
+	price.Quo(price, precision)
+
+	return &Result{ImpactRate: rate, ImpactAmount: amount, ExecutionPrice: price}, nil
+}
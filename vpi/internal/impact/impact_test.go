@@ -0,0 +1,121 @@
+package impact
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/storm-trade/config-discovery-client/types"
+	"github.com/test-go/testify/require"
+)
+
+type goldenVector struct {
+	Name   string `json:"name"`
+	Params struct {
+		MarketDepthLong  string `json:"marketDepthLong"`
+		MarketDepthShort string `json:"marketDepthShort"`
+		Spread           string `json:"spread"`
+		K                string `json:"k"`
+	} `json:"params"`
+	Trade struct {
+		Direction         string `json:"direction"`
+		Size              string `json:"size"`
+		LongOpenInterest  string `json:"longOpenInterest"`
+		ShortOpenInterest string `json:"shortOpenInterest"`
+		OraclePrice       string `json:"oraclePrice"`
+	} `json:"trade"`
+	Expected struct {
+		ImpactRate     string `json:"impactRate"`
+		ImpactAmount   string `json:"impactAmount"`
+		ExecutionPrice string `json:"executionPrice"`
+	} `json:"expected"`
+}
+
+func bigInt(t *testing.T, s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	require.True(t, ok, s)
+	return n
+}
+
+func TestGoldenVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/golden.json")
+	require.NoError(t, err)
+	var vectors []goldenVector
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			params := types.VPIParamsParsed{
+				MarketDepthLong:  bigInt(t, v.Params.MarketDepthLong),
+				MarketDepthShort: bigInt(t, v.Params.MarketDepthShort),
+				Spread:           bigInt(t, v.Params.Spread),
+				K:                bigInt(t, v.Params.K),
+			}
+			direction := Long
+			if v.Trade.Direction == "short" {
+				direction = Short
+			}
+
+			r, err := Compute(params, Trade{
+				Direction:         direction,
+				Size:              bigInt(t, v.Trade.Size),
+				LongOpenInterest:  bigInt(t, v.Trade.LongOpenInterest),
+				ShortOpenInterest: bigInt(t, v.Trade.ShortOpenInterest),
+				OraclePrice:       bigInt(t, v.Trade.OraclePrice),
+			})
+			require.NoError(t, err)
+			require.Equal(t, v.Expected.ImpactRate, r.ImpactRate.String())
+			require.Equal(t, v.Expected.ImpactAmount, r.ImpactAmount.String())
+			require.Equal(t, v.Expected.ExecutionPrice, r.ExecutionPrice.String())
+		})
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	params := types.VPIParamsParsed{MarketDepthLong: big.NewInt(1), MarketDepthShort: big.NewInt(0), Spread: big.NewInt(0), K: big.NewInt(1)}
+	trade := Trade{Size: big.NewInt(1), LongOpenInterest: big.NewInt(0), ShortOpenInterest: big.NewInt(0), OraclePrice: big.NewInt(1)}
+
+	_, err := Compute(params, trade)
+	require.NoError(t, err)
+
+	_, err = Compute(types.VPIParamsParsed{}, trade)
+	require.Error(t, err)
+
+	short := trade
+	short.Direction = Short
+	_, err = Compute(params, short)
+	require.EqualError(t, err, "short market depth must be positive")
+
+	negative := trade
+	negative.Size = big.NewInt(-1)
+	_, err = Compute(params, negative)
+	require.Error(t, err)
+
+	_, err = Compute(params, Trade{Size: big.NewInt(1)})
+	require.Error(t, err)
+
+	// A short impact of 100% or more would price the trade at zero.
+	params.MarketDepthShort = big.NewInt(1)
+	params.K = big.NewInt(Precision)
+	for _, size := range []int64{2, 5} {
+		short.Size = big.NewInt(size)
+		_, err = Compute(params, short)
+		require.True(t, errors.Is(err, ErrImpactTooLarge), "size %d", size)
+	}
+	_, err = Compute(params, trade)
+	require.NoError(t, err)
+}
+
+func TestComputeDoesNotMutateInputs(t *testing.T) {
+	params := types.VPIParamsParsed{MarketDepthLong: big.NewInt(1000), MarketDepthShort: big.NewInt(1000), Spread: big.NewInt(5), K: big.NewInt(7)}
+	trade := Trade{Size: big.NewInt(10), LongOpenInterest: big.NewInt(20), ShortOpenInterest: big.NewInt(3), OraclePrice: big.NewInt(Precision)}
+
+	_, err := Compute(params, trade)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), params.Spread.Int64())
+	require.Equal(t, int64(10), trade.Size.Int64())
+	require.Equal(t, int64(20), trade.LongOpenInterest.Int64())
+}